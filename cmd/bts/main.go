@@ -1,9 +1,26 @@
-// Bts scrapes data about flights from Bratislava Airport website https://www.bts.aero.
+// Bts scrapes data about flights from airport websites, by default from
+// Bratislava Airport website https://www.bts.aero.
+//
+// Usage:
+//
+//	bts [flags]
+//	bts stats -archive file
+//
+// The stats subcommand reports punctuality and cancellations of flights
+// archived via the -archive flag.
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/jreisinger/tools/internal/bts"
 )
@@ -11,18 +28,195 @@ import (
 var (
 	flightType = bts.FlightTypeFlag("type", bts.Both, "arrival or departure")
 	busy       = flag.Bool("busy", false, "show only the most busy hour")
+	histogram  = flag.Duration("histogram", 0, "show bar chart of flights in time windows of `size`, e.g. 15m, 30m or 1h")
+	step       = flag.Duration("step", 0, "start histogram windows every `interval` (default window size); smaller than size means sliding windows")
+	format     = flag.String("format", "table", "output `format`: table, json, csv or ical")
+	archive    = flag.String("archive", "", "append every scraped snapshot of flights to `file`")
+	airport    = bts.AirportFlag("airport", "bts", "IATA `code` of airport: "+strings.Join(bts.AirportCodes(), ", "))
+
+	dest    = flag.String("dest", "", "show only flights with destination matching `regexp` (case insensitive)")
+	airline = flag.String("airline", "", "show only flights of airline containing `name`")
+	number  = flag.String("number", "", "show only flight with `number`")
+	date    = flag.String("date", "", "show only flights on `date` (YYYY-MM-DD)")
+	from    = flag.String("from", "", "show only flights from `time` of day (HH:MM)")
+	to      = flag.String("to", "", "show only flights until `time` of day (HH:MM)")
+	delayed = flag.Bool("delayed", false, "show only delayed flights")
+
+	watch    = flag.Duration("watch", 0, "poll flights every `interval` and print changes")
+	maxDelay = flag.Duration("max-delay", 0, "when watching, report flights delayed more than `duration`")
+	command  = flag.String("exec", "", "when watching, run `command` with change description as the last argument, e.g. notify-send")
+	webhook  = flag.String("webhook", "", "when watching, POST changes as JSON to `URL`")
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "stats" {
+		stats(os.Args[2:])
+		return
+	}
 	flag.Parse()
+	if *command != "" && len(strings.Fields(*command)) == 0 {
+		log.Fatal("-exec: empty command")
+	}
+
+	filter, err := flightsFilter()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *watch > 0 {
+		watchFlights(filter)
+		return
+	}
 
-	flights, err := bts.GetFlights(*flightType)
+	flights, err := bts.GetFlights(*airport, *flightType)
+	if errors.Is(err, bts.ErrMarkup) {
+		log.Fatalf("%v; has the website changed?", err)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err := archiveFlights(flights); err != nil {
+		log.Fatal(err)
+	}
+	flights = filter(flights)
 
+	if *histogram > 0 {
+		if *step == 0 {
+			*step = *histogram
+		}
+		if err := bts.PrintHistogram(os.Stdout, flights.Histogram(*histogram, *step)); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	if *busy {
 		flights = flights.Busy()
 	}
-	flights.Print()
+	switch *format {
+	case "table":
+		err = flights.Print(os.Stdout)
+	case "json":
+		err = flights.WriteJSON(os.Stdout)
+	case "csv":
+		err = flights.WriteCSV(os.Stdout)
+	case "ical":
+		err = flights.WriteICal(os.Stdout)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// watchFlights polls flights and notifies about changes until interrupted.
+func watchFlights(filter func(bts.Flights) bts.Flights) {
+	notifiers := []func(bts.Event) error{bts.PrintNotifier(os.Stdout)}
+	if *command != "" {
+		args := strings.Fields(*command)
+		notifiers = append(notifiers, bts.CommandNotifier(args[0], args[1:]...))
+	}
+	if *webhook != "" {
+		notifiers = append(notifiers, bts.WebhookNotifier(*webhook))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	w := bts.Watcher{
+		Provider: *airport,
+		Type:     *flightType,
+		Interval: *watch,
+		MaxDelay: *maxDelay,
+		Filter:   filter,
+		Snapshot: archiveFlights,
+		Notify: func(e bts.Event) error {
+			var errs []error
+			for _, notify := range notifiers {
+				errs = append(errs, notify(e))
+			}
+			return errors.Join(errs...)
+		},
+		Error: func(err error) { log.Print(err) },
+	}
+	log.Printf("watching %s flights every %v", *flightType, *watch)
+	if err := w.Watch(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatal(err)
+	}
+}
+
+// archiveFlights appends flights to archive file if set by flag.
+func archiveFlights(flights bts.Flights) error {
+	if *archive == "" {
+		return nil
+	}
+	return bts.AppendArchive(*archive, bts.Snapshot{Time: time.Now().In(bts.Location), Type: *flightType, Flights: flights})
+}
+
+// stats prints statistics of archived flights.
+func stats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	file := fs.String("archive", "", "read flights from archive `file`")
+	fs.Parse(args)
+	if *file == "" {
+		log.Fatal("stats: supply archive file via -archive")
+	}
+
+	snapshots, err := bts.ReadArchive(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := bts.Analyze(snapshots).Print(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// flightsFilter returns function applying filters selected by flags.
+func flightsFilter() (func(bts.Flights) bts.Flights, error) {
+	var filters []func(bts.Flights) bts.Flights
+	if *dest != "" {
+		re, err := regexp.Compile("(?i)" + *dest)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, func(f bts.Flights) bts.Flights { return f.DestinationMatching(re) })
+	}
+	if *airline != "" {
+		filters = append(filters, func(f bts.Flights) bts.Flights { return f.Airline(*airline) })
+	}
+	if *number != "" {
+		filters = append(filters, func(f bts.Flights) bts.Flights { return f.Number(*number) })
+	}
+	if *date != "" {
+		d, err := time.ParseInLocation("2006-01-02", *date, bts.Location)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, func(f bts.Flights) bts.Flights { return f.On(d) })
+	}
+	if *from != "" || *to != "" {
+		start, end := time.Duration(0), 24*time.Hour-time.Minute
+		var err error
+		if *from != "" {
+			if start, err = bts.ParseClock(*from); err != nil {
+				return nil, err
+			}
+		}
+		if *to != "" {
+			if end, err = bts.ParseClock(*to); err != nil {
+				return nil, err
+			}
+		}
+		filters = append(filters, func(f bts.Flights) bts.Flights { return f.Between(start, end) })
+	}
+	if *delayed {
+		filters = append(filters, func(f bts.Flights) bts.Flights { return f.Delayed(0) })
+	}
+
+	return func(flights bts.Flights) bts.Flights {
+		for _, filter := range filters {
+			flights = filter(flights)
+		}
+		return flights
+	}, nil
 }
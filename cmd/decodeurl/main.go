@@ -1,4 +1,4 @@
-// Decodeurl decodes URL to human readable form.
+// Decodeurl decodes URL to human readable form or modifies and re-encodes it.
 package main
 
 import "github.com/jreisinger/tools/internal/decodeurl"
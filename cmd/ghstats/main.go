@@ -1,17 +1,54 @@
-// Ghstats provides statistics about GitHub repositories.
+/*
+Ghstats provides statistics about GitHub repositories of users and organisations.
+
+Usage:
+
+	$ ghstats jreisinger
+	$ ghstats -forks=false -archived=false jreisinger golang
+	$ ghstats -sort pushed,stars:asc -format markdown jreisinger
+	$ ghstats -history ~/.ghstats.json -html report.html jreisinger
+	$ ghstats -enterprise https://github.example.com -token-file ~/.ghe-token team
+	$ ghstats -app-id 12345 -app-key app.private-key.pem myorg
+
+The token is taken from -token-file, GITHUB_TOKEN environment variable or the
+gh CLI configuration, in this order. With -app-id and -app-key ghstats
+authenticates as a GitHub App installation instead.
+*/
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
 
 	"github.com/jreisinger/tools/internal/ghstats"
 )
 
 var (
 	n = flag.Int("n", 10, "show top `N` repositories")
-	c = flag.Int("c", 3, "sort by column number `N`")
+	j = flag.Int("j", 10, "fetch up to `N` repositories concurrently")
+	s = flag.String("sort", "stars", "comma separated `keys` to sort by; column name optionally followed by :asc or :desc")
+	f = flag.String("format", "table", "output `format`: table, json, csv or markdown")
+	l = flag.String("columns", "", "comma separated `list` of columns to print (default "+strings.Join(ghstats.DefaultColumns, ",")+", plus "+strings.Join(ghstats.TextColumns, ",")+" in table and markdown); one of "+strings.Join(ghstats.ColumnNames(), ", "))
+	h = flag.String("history", "", "store daily traffic in JSON `file` and show long-term totals and trends")
+	r = flag.String("html", "", "also write HTML report with charts to `file`")
+
+	forks    = flag.Bool("forks", true, "include forked repositories")
+	archived = flag.Bool("archived", true, "include archived repositories")
+	private  = flag.Bool("private", true, "include private repositories")
+
+	enterprise      = flag.String("enterprise", "", "GitHub Enterprise Server `URL`")
+	tokenFile       = flag.String("token-file", "", "read token from `file`")
+	appID           = flag.Int64("app-id", 0, "authenticate as GitHub App with `ID`")
+	appKey          = flag.String("app-key", "", "GitHub App private key `file`")
+	appInstallation = flag.Int64("app-installation", 0, "GitHub App installation `ID` (default first installation)")
 )
 
 func main() {
@@ -20,21 +57,143 @@ func main() {
 
 	flag.Parse()
 
-	if len(flag.Args()) != 1 {
-		log.Fatalf("supply GitHub username")
+	if len(flag.Args()) == 0 {
+		log.Fatalf("supply GitHub users or organisations")
+	}
+	owners := flag.Args()
+
+	auth, err := getAuth()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := ghstats.NewClient(ctx, *enterprise, auth)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	columns := slices.Clone(ghstats.DefaultColumns)
+	if *f == "table" || *f == "markdown" {
+		columns = append(columns, ghstats.TextColumns...)
+	}
+	if *h != "" {
+		columns = append(columns, ghstats.HistoryColumns...)
+	}
+	if *l != "" {
+		columns = strings.Split(*l, ",")
+	}
+	sortKeys := strings.Split(*s, ",")
+
+	// Fetch only details that are shown, sorted by, stored in history or
+	// charted to save API rate limit.
+	metrics, err := ghstats.ColumnMetrics(append(slices.Clone(columns), sortKeys...))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *h != "" || *r != "" {
+		metrics |= ghstats.MetricTraffic | ghstats.MetricReleases
+	}
+
+	opts := ghstats.Options{
+		Filter:      ghstats.Filter{Forks: *forks, Archived: *archived, Private: *private},
+		Concurrency: *j,
+		Metrics:     metrics,
+	}
+	stats, err := ghstats.Get(ctx, client, owners, opts)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatal(err)
+	}
+	for _, stat := range stats {
+		if err := stat.Err(); err != nil {
+			log.Printf("%s/%s: incomplete: %v", stat.Owner, stat.Repository, err)
+		}
+	}
+
+	if *h != "" {
+		history, err := ghstats.LoadHistory(*h)
+		if err != nil {
+			log.Fatal(err)
+		}
+		history.Merge(stats)
+		if err := history.Save(*h); err != nil {
+			log.Fatal(err)
+		}
 	}
-	user := flag.Args()[0]
 
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		log.Fatalf("set GITHUB_TOKEN environment variable")
+	if err := stats.Sort(sortKeys...); err != nil {
+		log.Fatal(err)
 	}
 
-	stats, err := ghstats.Get(user, token)
+	switch *f {
+	case "table":
+		err = stats.Print(os.Stdout, *n, columns)
+	case "json":
+		err = stats.WriteJSON(os.Stdout, *n, columns)
+	case "csv":
+		err = stats.WriteCSV(os.Stdout, *n, columns)
+	case "markdown":
+		err = stats.WriteMarkdown(os.Stdout, *n, columns)
+	default:
+		err = fmt.Errorf("unknown format %q", *f)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	stats.Sort(*c)
-	stats.Print(*n)
+	if *r != "" {
+		if err := writeHTML(*r, stats, columns); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// writeHTML writes HTML report of stats to file.
+func writeHTML(file string, stats ghstats.Stats, columns []string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := stats.WriteHTML(f, *n, columns); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// getAuth returns GitHub App credentials or a token from the first source
+// that has it.
+func getAuth() (ghstats.Auth, error) {
+	if *appID != 0 {
+		if *appKey == "" {
+			return ghstats.Auth{}, errors.New("-app-id requires -app-key")
+		}
+		key, err := os.ReadFile(*appKey)
+		if err != nil {
+			return ghstats.Auth{}, err
+		}
+		return ghstats.Auth{AppID: *appID, InstallationID: *appInstallation, PrivateKey: key}, nil
+	}
+
+	if *tokenFile != "" {
+		token, err := ghstats.TokenFromFile(*tokenFile)
+		return ghstats.Auth{Token: token}, err
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return ghstats.Auth{Token: token}, nil
+	}
+	host := "github.com"
+	if *enterprise != "" {
+		u, err := url.Parse(*enterprise)
+		if err != nil {
+			return ghstats.Auth{}, err
+		}
+		host = u.Hostname()
+	}
+	if token, err := ghstats.TokenFromGh(host); err == nil {
+		return ghstats.Auth{Token: token}, nil
+	}
+	return ghstats.Auth{}, errors.New("set GITHUB_TOKEN environment variable, use -token-file or log in with gh auth login")
 }
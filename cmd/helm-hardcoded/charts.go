@@ -0,0 +1,77 @@
+package main
+
+import (
+	"path"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// file is a chart file to search for hardcoded values.
+type file struct {
+	Chart    *chart.Chart
+	Path     string // relative to the root chart, e.g. charts/sub/templates/x.yaml
+	Data     []byte
+	Template bool // Go template, as opposed to raw file
+}
+
+// fileOptions select which files besides templates are searched.
+type fileOptions struct {
+	CRDs  bool // crds/ directories
+	Files bool // raw files like files/ and values.yaml of subcharts
+}
+
+// allCharts returns ch and all its nested dependencies, depth first.
+func allCharts(ch *chart.Chart) []*chart.Chart {
+	charts := []*chart.Chart{ch}
+	for _, dep := range ch.Dependencies() {
+		charts = append(charts, allCharts(dep)...)
+	}
+	return charts
+}
+
+// chartFiles returns files of root chart and all its nested dependencies.
+func chartFiles(root *chart.Chart, opts fileOptions) []file {
+	var files []file
+	for _, ch := range allCharts(root) {
+		for _, tpl := range ch.Templates {
+			files = append(files, file{Chart: ch, Path: relPath(root, ch, tpl.Name), Data: tpl.Data, Template: true})
+		}
+		for _, f := range ch.Files {
+			isCRD := strings.HasPrefix(f.Name, "crds/")
+			if isCRD && opts.CRDs || !isCRD && opts.Files {
+				files = append(files, file{Chart: ch, Path: relPath(root, ch, f.Name), Data: f.Data})
+			}
+		}
+		if opts.Files && !ch.IsRoot() {
+			for _, f := range ch.Raw {
+				if f.Name == chartutil.ValuesfileName {
+					files = append(files, file{Chart: ch, Path: relPath(root, ch, f.Name), Data: f.Data})
+				}
+			}
+		}
+	}
+	return files
+}
+
+// relPath returns path of chart's file name relative to the root chart.
+func relPath(root, ch *chart.Chart, name string) string {
+	return chartRelPath(root, path.Join(ch.ChartFullPath(), name))
+}
+
+// chartRelPath strips the root chart's name from fullPath, e.g.
+// mychart/charts/sub/templates/x.yaml becomes charts/sub/templates/x.yaml.
+func chartRelPath(root *chart.Chart, fullPath string) string {
+	return strings.TrimPrefix(fullPath, root.ChartFullPath()+"/")
+}
+
+// chartName returns name of the chart fullPath belongs to, e.g. sub for
+// mychart/charts/sub/templates/x.yaml.
+func chartName(fullPath string) string {
+	if i := strings.LastIndex(fullPath, "/charts/"); i >= 0 {
+		fullPath = fullPath[i+len("/charts/"):]
+	}
+	name, _, _ := strings.Cut(fullPath, "/")
+	return name
+}
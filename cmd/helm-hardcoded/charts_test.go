@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func newChart(name string) *chart.Chart {
+	return &chart.Chart{
+		Metadata:  &chart.Metadata{Name: name},
+		Templates: []*chart.File{{Name: "templates/cm.yaml"}},
+		Files:     []*chart.File{{Name: "crds/crd.yaml"}, {Name: "files/x.txt"}},
+		Raw:       []*chart.File{{Name: "values.yaml"}},
+	}
+}
+
+func TestChartFiles(t *testing.T) {
+	root, sub, subsub := newChart("root"), newChart("sub"), newChart("subsub")
+	root.AddDependency(sub)
+	sub.AddDependency(subsub)
+
+	testcases := []struct {
+		opts fileOptions
+		want []string
+	}{
+		{
+			opts: fileOptions{},
+			want: []string{
+				"templates/cm.yaml",
+				"charts/sub/templates/cm.yaml",
+				"charts/sub/charts/subsub/templates/cm.yaml",
+			},
+		},
+		{
+			opts: fileOptions{CRDs: true, Files: true},
+			want: []string{
+				"templates/cm.yaml",
+				"crds/crd.yaml",
+				"files/x.txt",
+				"charts/sub/templates/cm.yaml",
+				"charts/sub/crds/crd.yaml",
+				"charts/sub/files/x.txt",
+				"charts/sub/values.yaml",
+				"charts/sub/charts/subsub/templates/cm.yaml",
+				"charts/sub/charts/subsub/crds/crd.yaml",
+				"charts/sub/charts/subsub/files/x.txt",
+				"charts/sub/charts/subsub/values.yaml",
+			},
+		},
+	}
+	for _, tc := range testcases {
+		var got []string
+		for _, f := range chartFiles(root, tc.opts) {
+			got = append(got, f.Path)
+		}
+		if !cmp.Equal(tc.want, got) {
+			t.Errorf("%+v: %s", tc.opts, cmp.Diff(tc.want, got))
+		}
+	}
+}
+
+func TestSearchFindingsFailsOnBrokenTemplate(t *testing.T) {
+	root := newChart("root")
+	root.Templates[0].Data = []byte("image: nginx:{{ .Values.tag")
+	if _, err := searchFindings(root, fileOptions{}, "nginx"); err == nil {
+		t.Error("want error for template that can't be parsed")
+	}
+}
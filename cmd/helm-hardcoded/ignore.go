@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strings"
+)
+
+// ignoreFileName is the default file, within a chart folder, listing paths
+// where hardcoded values are intentional.
+const ignoreFileName = ".helm-hardcoded-ignore"
+
+// ignoreAnnotation marks a line where hardcoded value is intentional, e.g.
+//
+//	image: busybox # helm-hardcoded:ignore
+//	image: busybox {{- /* helm-hardcoded:ignore */}}
+const ignoreAnnotation = "helm-hardcoded:ignore"
+
+// ignorePatterns are path patterns (see path.Match) of chart files to ignore.
+// A pattern ending with a slash matches all files within that directory.
+type ignorePatterns []string
+
+// readIgnoreFile reads patterns from file, one per line. Empty lines and lines
+// starting with # are skipped.
+func readIgnoreFile(file string) (ignorePatterns, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns ignorePatterns
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, s.Err()
+}
+
+// match reports whether p matches any of the patterns.
+func (patterns ignorePatterns) match(p string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "/") {
+			if strings.HasPrefix(p, pattern) {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns findings that are neither in ignored paths nor on lines
+// annotated with ignoreAnnotation.
+func (patterns ignorePatterns) filter(findings []finding) []finding {
+	var filtered []finding
+	for _, f := range findings {
+		if patterns.match(f.Path) {
+			continue
+		}
+		if f.Field == "" && strings.Contains(f.Text, ignoreAnnotation) {
+			continue
+		}
+		filtered = append(filtered, f)
+	}
+	return filtered
+}
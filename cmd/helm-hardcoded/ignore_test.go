@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIgnorePatternsFilter(t *testing.T) {
+	patterns := ignorePatterns{"templates/_helpers.tpl", "charts/sub/", "crds/*.yaml"}
+	findings := []finding{
+		{Path: "templates/_helpers.tpl", Line: 1, Text: "docker.io"},
+		{Path: "templates/deploy.yaml", Line: 1, Text: "image: docker.io/app"},
+		{Path: "templates/deploy.yaml", Line: 2, Text: "image: docker.io/app # helm-hardcoded:ignore"},
+		{Path: "charts/sub/templates/deploy.yaml", Line: 1, Text: "docker.io"},
+		{Path: "charts/subsub/templates/deploy.yaml", Line: 1, Text: "docker.io"},
+		{Path: "crds/crd.yaml", Line: 1, Text: "docker.io"},
+	}
+	want := []finding{
+		{Path: "templates/deploy.yaml", Line: 1, Text: "image: docker.io/app"},
+		{Path: "charts/subsub/templates/deploy.yaml", Line: 1, Text: "docker.io"},
+	}
+	got := patterns.filter(findings)
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
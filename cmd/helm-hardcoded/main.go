@@ -4,78 +4,157 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"helm.sh/helm/v3/pkg/chart"
-	"helm.sh/helm/v3/pkg/chart/loader"
 )
 
 func main() {
 	log.SetFlags(0)
 	log.SetPrefix("helm-hardcoded: ")
 
-	flagChart := flag.String("chart", ".", "helm chart to search for hardcoded value; folder or .tgz")
-	flagVerbose := flag.Bool("verbose", false, "print also lines containing value")
+	flagChart := flag.String("chart", ".", "helm chart to search for hardcoded value; folder, .tgz, repo/chart, oci:// reference or URL")
+	flagVersion := flag.String("version", "", "version of chart to pull from repository (default latest)")
+	flagRepo := flag.String("repo", "", "chart repository `URL` where to find the chart")
+	flagVerbose := flag.Bool("verbose", false, "print also line:column and lines containing value")
+	flagCRDs := flag.Bool("crds", false, "search also CRDs in crds/ directories")
+	flagFiles := flag.Bool("files", false, "search also raw files, like files/, and values.yaml of subcharts")
+	flagRender := flag.Bool("render", false, "render chart with default and mutated values and report fields that stay the same")
+	flagOutput := flag.String("output", "text", "output format: text, json or sarif")
+	flagIgnore := flag.String("ignore", "", "file with chart paths to ignore (default "+ignoreFileName+" in chart folder)")
 	flag.Parse()
 
-	if len(flag.Args()) != 1 {
-		log.Fatalf("supply value to search")
+	if *flagRender && len(flag.Args()) > 1 || !*flagRender && len(flag.Args()) != 1 {
+		fatalf("supply value to search")
+	}
+	var value string
+	if len(flag.Args()) > 0 {
+		value = flag.Args()[0]
+	}
+
+	switch *flagOutput {
+	case "text", "json", "sarif":
+	default:
+		fatalf("unknown output format: %s", *flagOutput)
 	}
-	value := flag.Args()[0]
 
-	loadedChart, err := loader.Load(*flagChart)
+	loadedChart, err := loadChart(*flagChart, *flagVersion, *flagRepo)
 	if err != nil {
-		log.Fatalf("loading chart: %v", err)
+		fatalf("loading chart: %v", err)
 	}
 
-	var allCharts []*chart.Chart
-	allCharts = append(allCharts, loadedChart)
-	allCharts = append(allCharts, loadedChart.Dependencies()...)
-
-	for _, ch := range allCharts {
-		for _, tpl := range ch.Templates {
-			lines := linesWithHardcodedValue(string(tpl.Data), value)
-			n := len(lines)
-			if n > 0 {
-				tplPath := filepath.Join(ch.ChartFullPath(), tpl.Name)
-				fmt.Printf("%s (%d %s)\n", removeFirstStringBeforeSlash(tplPath), n, formatLines(n))
-				if *flagVerbose {
-					for _, line := range lines {
-						fmt.Println(line)
-					}
-				}
-			}
+	ignoreFile := *flagIgnore
+	if ignoreFile == "" {
+		defaultFile := filepath.Join(*flagChart, ignoreFileName)
+		if fi, err := os.Stat(defaultFile); err == nil && fi.Mode().IsRegular() {
+			ignoreFile = defaultFile
+		}
+	}
+	var ignore ignorePatterns
+	if ignoreFile != "" {
+		ignore, err = readIgnoreFile(ignoreFile)
+		if err != nil {
+			fatalf("reading ignore file: %v", err)
 		}
 	}
-}
 
-func formatLines(count int) string {
-	if count == 1 {
-		return "line"
+	var findings []finding
+	if *flagRender {
+		findings, err = renderFindings(loadedChart, value)
+	} else {
+		findings, err = searchFindings(loadedChart, fileOptions{CRDs: *flagCRDs, Files: *flagFiles}, value)
 	}
-	return "lines"
-}
+	if err != nil {
+		fatalf("%v", err)
+	}
+	findings = ignore.filter(findings)
 
-func removeFirstStringBeforeSlash(path string) string {
-	parts := strings.SplitN(path, "/", 2)
-	if len(parts) > 1 {
-		return parts[1]
+	switch *flagOutput {
+	case "text":
+		writeText(os.Stdout, findings, *flagVerbose)
+	case "json":
+		err = writeJSON(os.Stdout, findings)
+	case "sarif":
+		err = writeSARIF(os.Stdout, findings)
+	}
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	if len(findings) > 0 {
+		os.Exit(exitFindings)
 	}
-	return path
 }
 
-func linesWithHardcodedValue(templateContent, value string) []string {
-	var results []string
-	lines := strings.Split(templateContent, "\n")
-	for _, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
-		if strings.Contains(trimmedLine, "{{") && strings.Contains(trimmedLine, "}}") {
-			continue
+// Exit statuses. Errors use a different status than findings so CI can tell
+// a broken run from a failed check. Invalid flags exit with 2 too.
+const (
+	exitFindings = 1
+	exitError    = 2
+)
+
+// fatalf logs error and exits with exitError.
+func fatalf(format string, v ...any) {
+	log.Printf(format, v...)
+	os.Exit(exitError)
+}
+
+// searchFindings searches chart files for value. A template that can't be
+// parsed is an error so that it's not silently left unchecked.
+func searchFindings(root *chart.Chart, opts fileOptions, value string) ([]finding, error) {
+	var findings []finding
+	for _, f := range chartFiles(root, opts) {
+		matches := plainValues(string(f.Data), value)
+		if f.Template {
+			var err error
+			matches, err = hardcodedValues(f.Path, string(f.Data), value)
+			if err != nil {
+				return nil, fmt.Errorf("parsing template: %v", err)
+			}
 		}
-		if strings.Contains(trimmedLine, value) {
-			results = append(results, line)
+		for _, m := range matches {
+			findings = append(findings, finding{
+				Chart:  f.Chart.Name(),
+				Path:   f.Path,
+				Line:   m.Line,
+				Column: m.Column,
+				Text:   m.Text,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// renderFindings returns manifest fields that don't change when chart values
+// change. If value is not empty only fields containing it are returned.
+func renderFindings(root *chart.Chart, value string) ([]finding, error) {
+	fields, err := unchangedFields(root)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []finding
+	for _, name := range names {
+		for _, f := range fields[name] {
+			if !strings.Contains(f.Value, value) {
+				continue
+			}
+			findings = append(findings, finding{
+				Chart: chartName(name),
+				Path:  chartRelPath(root, name),
+				Field: f.Path,
+				Text:  f.Value,
+			})
 		}
 	}
-	return results
+	return findings, nil
 }
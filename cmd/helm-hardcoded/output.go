@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// finding is a hardcoded value found in a chart. It's either a line in a
+// chart file or a field of a rendered manifest (in render mode).
+type finding struct {
+	Chart  string `json:"chart"`
+	Path   string `json:"path"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+	Field  string `json:"field,omitempty"`
+	Text   string `json:"text"` // whole line or field value
+}
+
+// writeText writes findings grouped by path in a human friendly form.
+func writeText(w io.Writer, findings []finding, verbose bool) {
+	for i := 0; i < len(findings); {
+		j := i
+		for j < len(findings) && findings[j].Path == findings[i].Path {
+			j++
+		}
+		group := findings[i:j]
+		i = j
+
+		if group[0].Field != "" {
+			fmt.Fprintf(w, "%s (%d %s)\n", group[0].Path, len(group), formatCount(len(group), "field"))
+		} else {
+			n := countLines(group)
+			fmt.Fprintf(w, "%s (%d %s)\n", group[0].Path, n, formatCount(n, "line"))
+		}
+		if !verbose {
+			continue
+		}
+		for _, f := range group {
+			if f.Field != "" {
+				fmt.Fprintf(w, "%s: %s = %s\n", f.Path, f.Field, f.Text)
+			} else {
+				fmt.Fprintf(w, "%s:%d:%d: %s\n", f.Path, f.Line, f.Column, f.Text)
+			}
+		}
+	}
+}
+
+// writeJSON writes findings as JSON array.
+func writeJSON(w io.Writer, findings []finding) error {
+	if findings == nil {
+		findings = []finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(findings)
+}
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifRuleID  = "hardcoded-value"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Name           string      `json:"name"`
+			InformationURI string      `json:"informationUri"`
+			Rules          []sarifRule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations"`
+	Properties struct {
+		Chart string `json:"chart"`
+	} `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *sarifRegion `json:"region,omitempty"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+	StartLine   int           `json:"startLine"`
+	StartColumn int           `json:"startColumn"`
+	Snippet     *sarifMessage `json:"snippet,omitempty"`
+}
+
+// writeSARIF writes findings in the Static Analysis Results Interchange Format
+// understood by CI systems like GitHub code scanning.
+func writeSARIF(w io.Writer, findings []finding) error {
+	var run sarifRun
+	run.Tool.Driver.Name = "helm-hardcoded"
+	run.Tool.Driver.InformationURI = "https://github.com/jreisinger/tools/tree/main/cmd/helm-hardcoded"
+	run.Tool.Driver.Rules = []sarifRule{
+		{ID: sarifRuleID, ShortDescription: sarifMessage{Text: "Hardcoded value in helm chart"}},
+	}
+	run.Results = []sarifResult{}
+
+	for _, f := range findings {
+		r := sarifResult{RuleID: sarifRuleID, Level: "warning"}
+		r.Properties.Chart = f.Chart
+		var loc sarifLocation
+		loc.PhysicalLocation.ArtifactLocation.URI = f.Path
+		if f.Field != "" {
+			r.Message.Text = fmt.Sprintf("field %s has hardcoded value %q", f.Field, f.Text)
+		} else {
+			r.Message.Text = fmt.Sprintf("hardcoded value in %q", strings.TrimSpace(f.Text))
+			loc.PhysicalLocation.Region = &sarifRegion{
+				StartLine:   f.Line,
+				StartColumn: f.Column,
+				Snippet:     &sarifMessage{Text: f.Text},
+			}
+		}
+		r.Locations = []sarifLocation{loc}
+		run.Results = append(run.Results, r)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{run}})
+}
+
+// formatCount returns singular or plural form of noun.
+func formatCount(count int, noun string) string {
+	if count == 1 {
+		return noun
+	}
+	return noun + "s"
+}
+
+// countLines returns the number of distinct lines among findings.
+func countLines(findings []finding) int {
+	lines := make(map[int]bool)
+	for _, f := range findings {
+		lines[f.Line] = true
+	}
+	return len(lines)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var outputFindings = []finding{
+	{Chart: "app", Path: "templates/deploy.yaml", Line: 7, Column: 14, Text: "  image: docker.io/app"},
+	{Chart: "sub", Path: "charts/sub/templates/svc.yaml", Field: "spec.type", Text: "ClusterIP"},
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, outputFindings); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]any{
+		{"chart": "app", "path": "templates/deploy.yaml", "line": 7.0, "column": 14.0, "text": "  image: docker.io/app"},
+		{"chart": "sub", "path": "charts/sub/templates/svc.yaml", "field": "spec.type", "text": "ClusterIP"},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	buf.Reset()
+	if err := writeJSON(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("no findings: got %q, want %q", got, "[]\n")
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeSARIF(&buf, outputFindings); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region map[string]any `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+				Properties map[string]any `json:"properties"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != "2.1.0" || len(got.Runs) != 1 || len(got.Runs[0].Results) != 2 {
+		t.Fatalf("unexpected SARIF log:\n%s", buf.String())
+	}
+
+	line, field := got.Runs[0].Results[0], got.Runs[0].Results[1]
+	if line.RuleID != sarifRuleID {
+		t.Errorf("got rule %q, want %q", line.RuleID, sarifRuleID)
+	}
+	wantRegion := map[string]any{
+		"startLine":   7.0,
+		"startColumn": 14.0,
+		"snippet":     map[string]any{"text": "  image: docker.io/app"},
+	}
+	if diff := cmp.Diff(wantRegion, line.Locations[0].PhysicalLocation.Region); diff != "" {
+		t.Errorf("line region: %s", diff)
+	}
+	if uri := line.Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "templates/deploy.yaml" {
+		t.Errorf("got uri %q", uri)
+	}
+	if region := field.Locations[0].PhysicalLocation.Region; region != nil {
+		t.Errorf("field finding has region %v", region)
+	}
+	for i, r := range got.Runs[0].Results {
+		want := map[string]any{"chart": outputFindings[i].Chart}
+		if diff := cmp.Diff(want, r.Properties); diff != "" {
+			t.Errorf("result %d properties: %s", i, diff)
+		}
+	}
+}
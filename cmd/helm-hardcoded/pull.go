@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io"
+	"os"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// loadChart loads chart from local folder or .tgz. If ref is not a local
+// path, or repoURL is set, the chart is pulled first (see pullChart).
+func loadChart(ref, version, repoURL string) (*chart.Chart, error) {
+	if _, err := os.Stat(ref); err == nil && repoURL == "" {
+		return loader.Load(ref)
+	}
+
+	dir, err := os.MkdirTemp("", "helm-hardcoded")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	saved, err := pullChart(cli.New(), ref, version, repoURL, dir)
+	if err != nil {
+		return nil, err
+	}
+	return loader.Load(saved)
+}
+
+// pullChart downloads chart into dest folder and returns path of the
+// downloaded .tgz. Ref is either repo/chart, using repositories added via
+// helm repo add, an oci:// reference or a chart URL. If repoURL is not empty,
+// ref is a chart name within that repository. Empty version means latest.
+// Opts are added to options of the OCI registry client.
+func pullChart(settings *cli.EnvSettings, ref, version, repoURL, dest string, opts ...registry.ClientOption) (string, error) {
+	getters := getter.All(settings)
+
+	if repoURL != "" {
+		chartURL, err := repo.FindChartInRepoURL(repoURL, ref, version, "", "", "", getters)
+		if err != nil {
+			return "", err
+		}
+		ref = chartURL
+	}
+
+	registryClient, err := registry.NewClient(append([]registry.ClientOption{
+		registry.ClientOptCredentialsFile(settings.RegistryConfig),
+		registry.ClientOptWriter(io.Discard),
+	}, opts...)...)
+	if err != nil {
+		return "", err
+	}
+
+	dl := downloader.ChartDownloader{
+		Out:              io.Discard,
+		Verify:           downloader.VerifyNever,
+		Getters:          getters,
+		Options:          []getter.Option{getter.WithRegistryClient(registryClient)},
+		RegistryClient:   registryClient,
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+	}
+	saved, _, err := dl.DownloadTo(ref, version, dest)
+	return saved, err
+}
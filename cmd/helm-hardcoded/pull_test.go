@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// newChartRepo starts a chart repository serving mychart versions 0.1.0 and
+// 0.2.0. It returns the repository's URL.
+func newChartRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, version := range []string{"0.1.0", "0.2.0"} {
+		ch := &chart.Chart{
+			Metadata: &chart.Metadata{APIVersion: "v2", Name: "mychart", Version: version},
+			Templates: []*chart.File{
+				{Name: "templates/cm.yaml", Data: []byte("kind: ConfigMap\n")},
+			},
+		}
+		if _, err := chartutil.Save(ch, dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	t.Cleanup(srv.Close)
+
+	index, err := repo.IndexDirectory(dir, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := index.WriteFile(filepath.Join(dir, "index.yaml"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return srv.URL
+}
+
+// newSettings returns helm settings with repository named local pointing to
+// repoURL.
+func newSettings(t *testing.T, repoURL string) *cli.EnvSettings {
+	t.Helper()
+	dir := t.TempDir()
+	settings := cli.New()
+	settings.RepositoryConfig = filepath.Join(dir, "repositories.yaml")
+	settings.RepositoryCache = filepath.Join(dir, "cache")
+	settings.RegistryConfig = filepath.Join(dir, "registry.json")
+
+	f := repo.NewFile()
+	f.Add(&repo.Entry{Name: "local", URL: repoURL})
+	if err := f.WriteFile(settings.RepositoryConfig, 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := repo.NewChartRepository(f.Repositories[0], getter.All(settings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.CachePath = settings.RepositoryCache
+	if err := os.MkdirAll(r.CachePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.DownloadIndexFile(); err != nil {
+		t.Fatal(err)
+	}
+	return settings
+}
+
+func TestPullChart(t *testing.T) {
+	repoURL := newChartRepo(t)
+	settings := newSettings(t, repoURL)
+
+	testcases := []struct {
+		ref, version, repoURL string
+		want                  string // chart version
+	}{
+		{ref: "local/mychart", want: "0.2.0"},
+		{ref: "local/mychart", version: "0.1.0", want: "0.1.0"},
+		{ref: "mychart", version: "0.1.0", repoURL: repoURL, want: "0.1.0"},
+		{ref: repoURL + "/mychart-0.1.0.tgz", want: "0.1.0"},
+	}
+	for _, tc := range testcases {
+		saved, err := pullChart(settings, tc.ref, tc.version, tc.repoURL, t.TempDir())
+		if err != nil {
+			t.Errorf("%s: %v", tc.ref, err)
+			continue
+		}
+		ch, err := loader.Load(saved)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ch.Metadata.Version; got != tc.want {
+			t.Errorf("%s %s: want version %s, got %s", tc.ref, tc.version, tc.want, got)
+		}
+	}
+}
+
+// newOCIRegistry starts an OCI registry serving mychart versions 0.1.0 and
+// 0.2.0 as charts/mychart. Only pulling is supported. It returns the
+// registry's host.
+func newOCIRegistry(t *testing.T) string {
+	t.Helper()
+	const repository = "charts/mychart"
+	blobs := make(map[string][]byte)     // by digest
+	manifests := make(map[string][]byte) // by tag and digest
+	var tags []string
+	addBlob := func(data []byte) ocispec.Descriptor {
+		d := digest.FromBytes(data)
+		blobs[d.String()] = data
+		return ocispec.Descriptor{Digest: d, Size: int64(len(data))}
+	}
+	for _, version := range []string{"0.1.0", "0.2.0"} {
+		ch := &chart.Chart{
+			Metadata: &chart.Metadata{APIVersion: "v2", Name: "mychart", Version: version},
+			Templates: []*chart.File{
+				{Name: "templates/cm.yaml", Data: []byte("kind: ConfigMap\n")},
+			},
+		}
+		saved, err := chartutil.Save(ch, t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		layer, err := os.ReadFile(saved)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config, err := json.Marshal(ch.Metadata)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		manifest := ocispec.Manifest{
+			Versioned: specs.Versioned{SchemaVersion: 2},
+			MediaType: ocispec.MediaTypeImageManifest,
+			Config:    addBlob(config),
+			Layers:    []ocispec.Descriptor{addBlob(layer)},
+		}
+		manifest.Config.MediaType = registry.ConfigMediaType
+		manifest.Layers[0].MediaType = registry.ChartLayerMediaType
+		data, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifests[version] = data
+		manifests[digest.FromBytes(data).String()] = data
+		tags = append(tags, version)
+	}
+
+	prefix := "/v2/" + repository + "/"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "read-only registry", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Path == "/v2/" {
+			return
+		}
+		rest, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		kind, ref, _ := strings.Cut(rest, "/")
+		var data []byte
+		switch kind {
+		case "tags":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"name": repository, "tags": tags})
+			return
+		case "manifests":
+			data = manifests[ref]
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+		case "blobs":
+			data = blobs[ref]
+			w.Header().Set("Content-Type", "application/octet-stream")
+		}
+		if data == nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(data).String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestPullChartFromOCIRegistry(t *testing.T) {
+	host := newOCIRegistry(t)
+	settings := newSettings(t, newChartRepo(t))
+
+	testcases := []struct {
+		version string
+		want    string
+	}{
+		{version: "0.1.0", want: "0.1.0"},
+		{version: "", want: "0.2.0"},
+	}
+	ref := "oci://" + host + "/charts/mychart"
+	for _, tc := range testcases {
+		saved, err := pullChart(settings, ref, tc.version, "", t.TempDir(), registry.ClientOptPlainHTTP())
+		if err != nil {
+			t.Errorf("%s %s: %v", ref, tc.version, err)
+			continue
+		}
+		ch, err := loader.Load(saved)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ch.Metadata.Version; got != tc.want {
+			t.Errorf("%s %s: want version %s, got %s", ref, tc.version, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"sigs.k8s.io/yaml"
+)
+
+// field is a manifest field whose value doesn't depend on chart values.
+type field struct {
+	Path  string // e.g. spec.template.spec.containers[0].image
+	Value string
+}
+
+// mutatedSuffix is appended to all string values when rendering the chart for
+// the second time.
+const mutatedSuffix = "-mutated"
+
+// unchangedFields renders the chart with default values and with values where
+// every string and number leaf is perturbed. It returns fields of rendered
+// manifests that stay identical, keyed by template path. Such fields are
+// effectively hardcoded, even if they come from helpers. Boolean values are
+// not perturbed because that would enable or disable whole resources.
+func unchangedFields(ch *chart.Chart) (map[string][]field, error) {
+	if err := chartutil.ProcessDependencies(ch, nil); err != nil {
+		return nil, err
+	}
+	defaults, err := chartutil.CoalesceValues(ch, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := render(ch, nil)
+	if err != nil {
+		return nil, fmt.Errorf("rendering with default values: %v", err)
+	}
+	mutated, err := render(ch, mutate(defaults.AsMap()).(map[string]interface{}))
+	if err != nil {
+		return nil, fmt.Errorf("rendering with mutated values: %v", err)
+	}
+
+	fields := make(map[string][]field)
+	for name, manifest := range original {
+		docs1, err := parseDocuments(manifest)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		docs2, err := parseDocuments(mutated[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		for i := 0; i < len(docs1) && i < len(docs2); i++ {
+			fields[name] = append(fields[name], sameLeaves("", docs1[i], docs2[i])...)
+		}
+	}
+	return fields, nil
+}
+
+// render renders chart's templates with vals supplied by user.
+func render(ch *chart.Chart, vals map[string]interface{}) (map[string]string, error) {
+	options := chartutil.ReleaseOptions{Name: "release", Namespace: "default", IsInstall: true}
+	renderVals, err := chartutil.ToRenderValuesWithSchemaValidation(ch, vals, options, nil, vals != nil)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := engine.Render(ch, renderVals)
+	if err != nil {
+		return nil, err
+	}
+	manifests := make(map[string]string)
+	for name, content := range rendered {
+		if ext := path.Ext(name); ext != ".yaml" && ext != ".yml" {
+			continue // e.g. NOTES.txt
+		}
+		manifests[name] = content
+	}
+	return manifests, nil
+}
+
+// mutate returns a copy of v with all string and number leaves changed.
+func mutate(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = mutate(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = mutate(val)
+		}
+		return s
+	case string:
+		return v + mutatedSuffix
+	case float64:
+		return v + 1
+	case int:
+		return v + 1
+	case int64:
+		return v + 1
+	default:
+		return v
+	}
+}
+
+var documentSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// parseDocuments parses multi-document YAML manifest. Empty documents are
+// skipped.
+func parseDocuments(manifest string) ([]interface{}, error) {
+	var docs []interface{}
+	for _, d := range documentSeparator.Split(manifest, -1) {
+		var doc interface{}
+		if err := yaml.Unmarshal([]byte(d), &doc); err != nil {
+			return nil, err
+		}
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+// sameLeaves returns leaves of a that have the same value in b. Top-level
+// apiVersion and kind are ignored as they are always hardcoded.
+func sameLeaves(prefix string, a, b interface{}) []field {
+	var fields []field
+	switch a := a.(type) {
+	case map[string]interface{}:
+		bm, ok := b.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		keys := make([]string, 0, len(a))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prefix == "" && (k == "apiVersion" || k == "kind") {
+				continue
+			}
+			bv, ok := bm[k]
+			if !ok {
+				continue
+			}
+			p := k
+			if prefix != "" {
+				p = prefix + "." + k
+			}
+			fields = append(fields, sameLeaves(p, a[k], bv)...)
+		}
+	case []interface{}:
+		bs, ok := b.([]interface{})
+		if !ok {
+			return nil
+		}
+		for i := 0; i < len(a) && i < len(bs); i++ {
+			fields = append(fields, sameLeaves(fmt.Sprintf("%s[%d]", prefix, i), a[i], bs[i])...)
+		}
+	case nil:
+		// no value
+	default:
+		if reflect.DeepEqual(a, b) {
+			fields = append(fields, field{Path: prefix, Value: strings.TrimSpace(fmt.Sprint(a))})
+		}
+	}
+	return fields
+}
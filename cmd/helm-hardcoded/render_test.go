@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestUnchangedFields(t *testing.T) {
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: "v2", Name: "mychart", Version: "0.1.0"},
+		Values:   map[string]interface{}{"image": "repo/app", "replicas": 2.0},
+		Templates: []*chart.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "registry" }}docker.io{{ end }}`)},
+			{Name: "templates/deploy.yaml", Data: []byte(`kind: Deployment
+spec:
+  replicas: {{ .Values.replicas }}
+  image: {{ include "registry" . }}/{{ .Values.image }}
+  pullPolicy: IfNotPresent
+---
+kind: Service
+port: 80
+`)},
+			{Name: "templates/NOTES.txt", Data: []byte(`hardcoded`)},
+		},
+	}
+	got, err := unchangedFields(ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]field{
+		"mychart/templates/deploy.yaml": {
+			{Path: "spec.pullPolicy", Value: "IfNotPresent"},
+			{Path: "port", Value: "80"},
+		},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
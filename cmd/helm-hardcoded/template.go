@@ -0,0 +1,109 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// match is an occurrence of a hardcoded value in a template.
+type match struct {
+	Line   int    // 1-based line number
+	Column int    // 1-based column (in bytes)
+	Text   string // whole line containing the value
+}
+
+// hardcodedValues parses templateContent as a Go template and returns
+// occurrences of value in the literal text, i.e. outside of {{ ... }} actions.
+// Templates defined via {{ define }} are searched as well.
+func hardcodedValues(name, templateContent, value string) ([]match, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck // helm and sprig functions are not known here
+	treeSet := make(map[string]*parse.Tree)
+	if _, err := tree.Parse(templateContent, "", "", treeSet); err != nil {
+		return nil, err
+	}
+
+	var offsets []int
+	for _, t := range treeSet {
+		offsets = textOffsets(offsets, t.Root, value)
+	}
+	sort.Ints(offsets)
+
+	var matches []match
+	for _, off := range offsets {
+		matches = append(matches, position(templateContent, off))
+	}
+	return matches, nil
+}
+
+// plainValues returns occurrences of value in content that is not a template.
+func plainValues(content, value string) []match {
+	if value == "" {
+		return nil
+	}
+	var matches []match
+	for i := 0; ; {
+		j := strings.Index(content[i:], value)
+		if j < 0 {
+			break
+		}
+		matches = append(matches, position(content, i+j))
+		i += j + len(value)
+	}
+	return matches
+}
+
+// textOffsets traverses the template node tree and returns byte offsets of
+// value within text nodes.
+func textOffsets(offsets []int, node parse.Node, value string) []int {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return offsets
+		}
+		for _, c := range n.Nodes {
+			offsets = textOffsets(offsets, c, value)
+		}
+	case *parse.TextNode:
+		text := string(n.Text)
+		for i := 0; ; {
+			j := strings.Index(text[i:], value)
+			if j < 0 {
+				break
+			}
+			offsets = append(offsets, int(n.Pos)+i+j)
+			i += j + len(value)
+		}
+	case *parse.IfNode:
+		offsets = textOffsets(offsets, n.List, value)
+		offsets = textOffsets(offsets, n.ElseList, value)
+	case *parse.RangeNode:
+		offsets = textOffsets(offsets, n.List, value)
+		offsets = textOffsets(offsets, n.ElseList, value)
+	case *parse.WithNode:
+		offsets = textOffsets(offsets, n.List, value)
+		offsets = textOffsets(offsets, n.ElseList, value)
+	}
+	return offsets
+}
+
+// position converts byte offset within content into a match.
+func position(content string, offset int) match {
+	lineStart := strings.LastIndex(content[:offset], "\n") + 1
+	lineEnd := strings.Index(content[offset:], "\n")
+	if lineEnd < 0 {
+		lineEnd = len(content)
+	} else {
+		lineEnd += offset
+	}
+	return match{
+		Line:   strings.Count(content[:offset], "\n") + 1,
+		Column: offset - lineStart + 1,
+		Text:   content[lineStart:lineEnd],
+	}
+}
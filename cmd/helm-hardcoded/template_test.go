@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHardcodedValues(t *testing.T) {
+	testcases := []struct {
+		name     string
+		template string
+		want     []match
+	}{
+		{
+			name:     "plain text",
+			template: "image: repo/app:1.2.3",
+			want:     []match{{Line: 1, Column: 8, Text: "image: repo/app:1.2.3"}},
+		},
+		{
+			name:     "templated value",
+			template: "image: {{ .Values.image }}",
+			want:     nil,
+		},
+		{
+			name:     "value next to comment",
+			template: "kind: Pod\nimage: repo/app:1.2.3 {{- /* comment */}}",
+			want:     []match{{Line: 2, Column: 8, Text: "image: repo/app:1.2.3 {{- /* comment */}}"}},
+		},
+		{
+			name:     "value after action",
+			template: "host: {{ .Values.x }}.repo/app",
+			want:     []match{{Line: 1, Column: 23, Text: "host: {{ .Values.x }}.repo/app"}},
+		},
+		{
+			name:     "value inside action string",
+			template: `image: {{ default "repo/app" .Values.image }}`,
+			want:     nil,
+		},
+		{
+			name:     "value in branches and defines",
+			template: "{{ if .x }}repo/app{{ else }}repo/app{{ end }}\n{{ define \"img\" }}repo/app{{ end }}",
+			want: []match{
+				{Line: 1, Column: 12, Text: "{{ if .x }}repo/app{{ else }}repo/app{{ end }}"},
+				{Line: 1, Column: 30, Text: "{{ if .x }}repo/app{{ else }}repo/app{{ end }}"},
+				{Line: 2, Column: 19, Text: "{{ define \"img\" }}repo/app{{ end }}"},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := hardcodedValues("test.yaml", tc.template, "repo/app")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(tc.want, got) {
+				t.Error(cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
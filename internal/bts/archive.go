@@ -0,0 +1,214 @@
+package bts
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Snapshot is flights of Type as scraped at a time.
+type Snapshot struct {
+	Time    time.Time  `json:"time"`
+	Type    FlightType `json:"type"`
+	Flights Flights    `json:"flights"`
+}
+
+// AppendArchive appends snapshot to archive file with a JSON encoded snapshot
+// per line. The file is created if it doesn't exist.
+func AppendArchive(file string, s Snapshot) error {
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(s); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadArchive reads snapshots from archive file.
+func ReadArchive(file string) ([]Snapshot, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var snapshots []Snapshot
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 64*1024*1024)
+	for line := 1; s.Scan(); line++ {
+		var snapshot Snapshot
+		if err := json.Unmarshal(s.Bytes(), &snapshot); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", file, line, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, s.Err()
+}
+
+// onTimeDelay is the maximum delay of a flight considered on time.
+const onTimeDelay = 15 * time.Minute
+
+// DelayStats are punctuality statistics of a group of flights.
+type DelayStats struct {
+	Flights    int // not cancelled
+	OnTime     int // delayed at most 15 minutes
+	Cancelled  int
+	TotalDelay time.Duration // only delays, flights ahead of schedule count as zero
+}
+
+func (d *DelayStats) add(f Flight, cancelled bool) {
+	if cancelled {
+		d.Cancelled++
+		return
+	}
+	d.Flights++
+	if f.Delay() <= onTimeDelay {
+		d.OnTime++
+	}
+	d.TotalDelay += max(f.Delay(), 0)
+}
+
+// Punctuality returns percentage of flights on time.
+func (d DelayStats) Punctuality() float64 {
+	if d.Flights == 0 {
+		return 0
+	}
+	return float64(d.OnTime) * 100 / float64(d.Flights)
+}
+
+// AverageDelay returns average delay of flights.
+func (d DelayStats) AverageDelay() time.Duration {
+	if d.Flights == 0 {
+		return 0
+	}
+	return d.TotalDelay / time.Duration(d.Flights)
+}
+
+// ArchiveStats are statistics of flights in archived snapshots.
+type ArchiveStats struct {
+	From, To      time.Time // first and last snapshot
+	All           DelayStats
+	ByAirline     map[string]*DelayStats
+	ByDestination map[string]*DelayStats
+	ByHour        [24]DelayStats // hour of planned time
+	ByWeekday     [7]DelayStats  // weekday of planned time
+}
+
+// Analyze returns statistics of flights in snapshots sorted by time. Each
+// flight is counted once in the state it was seen last. A flight is
+// considered cancelled when it disappeared before its time even though later
+// snapshots of its type were taken after its time.
+func Analyze(snapshots []Snapshot) ArchiveStats {
+	stats := ArchiveStats{
+		ByAirline:     make(map[string]*DelayStats),
+		ByDestination: make(map[string]*DelayStats),
+	}
+	if len(snapshots) == 0 {
+		return stats
+	}
+	stats.From, stats.To = snapshots[0].Time, snapshots[len(snapshots)-1].Time
+
+	type seen struct {
+		flight Flight
+		at     time.Time // last snapshot with the flight
+	}
+	last := make(map[flightKey]seen)
+	var keys []flightKey
+	lastOfType := make(map[FlightType]time.Time) // last snapshot including type
+	for _, s := range snapshots {
+		for _, t := range []FlightType{Arrival, Departure} {
+			if s.Type.includes(t) {
+				lastOfType[t] = s.Time
+			}
+		}
+		for _, f := range s.Flights {
+			k := keyOf(f)
+			if _, ok := last[k]; !ok {
+				keys = append(keys, k)
+			}
+			last[k] = seen{flight: f, at: s.Time}
+		}
+	}
+
+	for _, k := range keys {
+		f, at := last[k].flight, last[k].at
+		cancelled := at.Before(f.Time()) && lastOfType[f.Type].After(f.Time())
+		stats.All.add(f, cancelled)
+		group(stats.ByAirline, f.Airline).add(f, cancelled)
+		group(stats.ByDestination, f.Destination).add(f, cancelled)
+		stats.ByHour[f.TimePlanned.Hour()].add(f, cancelled)
+		stats.ByWeekday[f.TimePlanned.Weekday()].add(f, cancelled)
+	}
+	return stats
+}
+
+func group(groups map[string]*DelayStats, name string) *DelayStats {
+	if groups[name] == nil {
+		groups[name] = new(DelayStats)
+	}
+	return groups[name]
+}
+
+// statsRow is a row of statistics table.
+type statsRow struct {
+	name string
+	DelayStats
+}
+
+// Print prints statistics tables to w.
+func (s ArchiveStats) Print(w io.Writer) error {
+	tw := new(tabwriter.Writer).Init(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Period: %s - %s\n\n", s.From.Format("2006-01-02 15:04"), s.To.Format("2006-01-02 15:04"))
+
+	const format = "%v\t%v\t%v\t%v\t%v\n"
+	printTable := func(name string, rows []statsRow) {
+		fmt.Fprintf(tw, format, name, "Flights", "On time", "Avg delay", "Cancelled")
+		fmt.Fprintf(tw, format, strings.Repeat("-", len(name)), "-------", "-------", "---------", "---------")
+		for _, r := range rows {
+			if r.Flights == 0 && r.Cancelled == 0 {
+				continue
+			}
+			avgDelay := strings.TrimPrefix(formatDelay(r.AverageDelay()), "+")
+			if avgDelay == "" {
+				avgDelay = "0m"
+			}
+			fmt.Fprintf(tw, format, r.name, r.Flights, fmt.Sprintf("%.0f%%", r.Punctuality()), avgDelay, r.Cancelled)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	printTable("All", []statsRow{{"all", s.All}})
+	printTable("Airline", groupRows(s.ByAirline))
+	printTable("Destination", groupRows(s.ByDestination))
+	var hours []statsRow
+	for h, d := range s.ByHour {
+		hours = append(hours, statsRow{fmt.Sprintf("%02d:00", h), d})
+	}
+	printTable("Hour", hours)
+	var weekdays []statsRow
+	for i := range s.ByWeekday {
+		d := time.Weekday((i + 1) % 7) // start on Monday
+		weekdays = append(weekdays, statsRow{d.String(), s.ByWeekday[d]})
+	}
+	printTable("Weekday", weekdays)
+	return tw.Flush()
+}
+
+// groupRows returns rows of groups sorted by name.
+func groupRows(groups map[string]*DelayStats) []statsRow {
+	var rows []statsRow
+	for name, d := range groups {
+		rows = append(rows, statsRow{name, *d})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].name < rows[j].name })
+	return rows
+}
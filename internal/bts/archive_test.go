@@ -0,0 +1,140 @@
+package bts
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchiveAppendAndRead(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "archive.jsonl")
+	snapshots := []Snapshot{
+		{Time: parseTime("2023-06-12 08:00"), Type: Both, Flights: exportFlights},
+		{Time: parseTime("2023-06-12 09:00"), Type: Departure, Flights: exportFlights[1:]},
+	}
+	for _, s := range snapshots {
+		if err := AppendArchive(file, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := ReadArchive(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(snapshots) {
+		t.Fatalf("ReadArchive() returned %d snapshots, want %d", len(got), len(snapshots))
+	}
+	for i := range snapshots {
+		var want, have bytes.Buffer
+		snapshots[i].Flights.WriteJSON(&want)
+		got[i].Flights.WriteJSON(&have)
+		if !got[i].Time.Equal(snapshots[i].Time) || got[i].Type != snapshots[i].Type || have.String() != want.String() {
+			t.Errorf("snapshot %d = %+v, want %+v", i, got[i], snapshots[i])
+		}
+	}
+	if got[0].Flights[0].TimeCurrent.Location() != Location {
+		t.Errorf("read time in %v, want %v", got[0].Flights[0].TimeCurrent.Location(), Location)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	f := func(number, airline, planned, current string) Flight {
+		fl := flight(number, planned, current)
+		fl.Airline = airline
+		return fl
+	}
+	snapshots := []Snapshot{
+		{Time: parseTime("2023-06-12 08:00"), Flights: Flights{
+			f("A1", "X", "09:00", ""),
+			f("A2", "X", "09:30", ""),
+			f("A3", "Y", "10:00", ""),
+		}},
+		{Time: parseTime("2023-06-12 09:40"), Flights: Flights{
+			f("A1", "X", "09:00", "09:20"),
+			f("A3", "Y", "10:00", ""),
+		}},
+		{Time: parseTime("2023-06-12 10:30"), Flights: Flights{
+			f("A3", "Y", "10:00", "10:05"),
+		}},
+	}
+	stats := Analyze(snapshots)
+
+	want := DelayStats{Flights: 2, OnTime: 1, Cancelled: 1, TotalDelay: 25 * time.Minute}
+	if stats.All != want {
+		t.Errorf("All = %+v, want %+v", stats.All, want)
+	}
+	if got := stats.All.AverageDelay(); got != 12*time.Minute+30*time.Second {
+		t.Errorf("AverageDelay() = %v, want 12m30s", got)
+	}
+	if got := *stats.ByAirline["X"]; got != (DelayStats{Flights: 1, Cancelled: 1, TotalDelay: 20 * time.Minute}) {
+		t.Errorf("ByAirline[X] = %+v", got)
+	}
+	if got := stats.ByHour[10].Punctuality(); got != 100 {
+		t.Errorf("ByHour[10].Punctuality() = %v, want 100", got)
+	}
+	if got := stats.ByWeekday[time.Monday].Flights; got != 2 {
+		t.Errorf("ByWeekday[Monday].Flights = %d, want 2", got)
+	}
+
+	var buf bytes.Buffer
+	if err := stats.Print(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Period: 2023-06-12 08:00 - 2023-06-12 10:30",
+		"all  2        50%      13m        1",
+		"X        1        0%       20m        1",
+		"Monday   2        50%",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Print() output doesn't contain %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestAnalyzeMixedTypes(t *testing.T) {
+	f := func(typ FlightType, number, planned string) Flight {
+		fl := flight(number, planned, "")
+		fl.Type = typ
+		return fl
+	}
+	snapshots := []Snapshot{
+		{Time: parseTime("2023-06-12 08:00"), Type: Arrival, Flights: Flights{
+			f(Arrival, "A1", "09:00"),
+			f(Arrival, "A2", "10:00"),
+		}},
+		// Arrivals are not scraped anymore so they didn't disappear.
+		{Time: parseTime("2023-06-12 11:00"), Type: Departure, Flights: Flights{
+			f(Departure, "D1", "12:00"),
+		}},
+		{Time: parseTime("2023-06-12 13:00"), Type: Departure, Flights: Flights{
+			f(Departure, "D2", "14:00"),
+		}},
+	}
+	stats := Analyze(snapshots)
+	want := DelayStats{Flights: 3, OnTime: 3, Cancelled: 1} // D1 disappeared
+	if stats.All != want {
+		t.Errorf("All = %+v, want %+v", stats.All, want)
+	}
+}
+
+func TestAnalyzeThroughFlight(t *testing.T) {
+	leg := func(typ FlightType, planned, current string) Flight {
+		fl := flight("T1", planned, current)
+		fl.Type = typ
+		return fl
+	}
+	snapshots := []Snapshot{
+		{Time: parseTime("2023-06-12 08:00"), Flights: Flights{
+			leg(Arrival, "09:00", "09:30"),
+			leg(Departure, "10:00", ""),
+		}},
+	}
+	stats := Analyze(snapshots)
+	want := DelayStats{Flights: 2, OnTime: 1, TotalDelay: 30 * time.Minute}
+	if stats.All != want {
+		t.Errorf("All = %+v, want %+v", stats.All, want)
+	}
+}
@@ -1,11 +1,16 @@
-// Package bts scrapes data about flights from Bratislava Airport web
+// Package bts gets data about flights from airports. Airports are supported
+// via providers; the first one scrapes Bratislava Airport web
 // https://www.bts.aero.
 package bts
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
+	_ "time/tzdata" // so Location is available everywhere
 
 	"golang.org/x/net/html"
 )
@@ -23,47 +28,107 @@ func (ft FlightType) String() string {
 	return [...]string{"arrival", "departure", "both"}[ft]
 }
 
+func (ft FlightType) MarshalText() ([]byte, error) {
+	return []byte(ft.String()), nil
+}
+
+func (ft *FlightType) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "arrival":
+		*ft = Arrival
+	case "departure":
+		*ft = Departure
+	case "both":
+		*ft = Both
+	default:
+		return fmt.Errorf("use arrival, departure or both")
+	}
+	return nil
+}
+
+// includes reports whether flights of type ft include flights of type t.
+func (ft FlightType) includes(t FlightType) bool {
+	return ft == Both || ft == t
+}
+
+// Flight is an arrival or a departure. Times are in the airport's time zone.
 type Flight struct {
 	Type        FlightType
 	Number      string
 	Destination string
-	Date        time.Time
+	Date        time.Time // midnight of the planned day
 	TimePlanned time.Time
-	TimeCurrent time.Time
+	TimeCurrent time.Time // zero if not known
 	Airline     string
 	Airplane    string
 }
 
+// Time returns current time of the flight if known, planned otherwise.
+func (f Flight) Time() time.Time {
+	if !f.TimeCurrent.IsZero() {
+		return f.TimeCurrent
+	}
+	return f.TimePlanned
+}
+
+// Delay returns how much later than planned the flight is. It's negative for
+// flights ahead of schedule and zero if current time is not known.
+func (f Flight) Delay() time.Duration {
+	if f.TimeCurrent.IsZero() || f.TimePlanned.IsZero() {
+		return 0
+	}
+	return f.TimeCurrent.Sub(f.TimePlanned)
+}
+
 type Flights []Flight
 
+// Location is the time zone of Bratislava Airport.
+var Location = mustLoadLocation("Europe/Bratislava")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
 const (
 	ArrivalsURL   = "https://www.bts.aero/en/flights/arrivals-departures/current-arrivals/"
 	DeparturesURL = "https://www.bts.aero/en/flights/arrivals-departures/current-departures/"
 )
 
-// GetFlights returns the flights of arrival and/or departure type.
-func GetFlights(ft FlightType) (Flights, error) {
-	switch ft {
-	case Arrival:
+// BTS is a provider scraping flights from Bratislava Airport website. Empty
+// URLs mean ArrivalsURL and DeparturesURL.
+type BTS struct {
+	ArrivalsURL   string
+	DeparturesURL string
+}
+
+func (b BTS) Arrivals() (Flights, error) {
+	if b.ArrivalsURL == "" {
 		return parse(ArrivalsURL)
-	case Departure:
+	}
+	return parse(b.ArrivalsURL)
+}
+
+func (b BTS) Departures() (Flights, error) {
+	if b.DeparturesURL == "" {
 		return parse(DeparturesURL)
-	case Both:
-		arrivals, err := parse(ArrivalsURL)
-		if err != nil {
-			return nil, err
-		}
-		departures, err := parse(DeparturesURL)
-		if err != nil {
-			return nil, err
-		}
-		var both Flights
-		both = append(both, arrivals...)
-		both = append(both, departures...)
-		return both, nil
-	default:
-		return nil, fmt.Errorf("unknown flight type")
 	}
+	return parse(b.DeparturesURL)
+}
+
+// ErrMarkup means that a page was parsed but its markup is not as expected,
+// probably because the website has changed.
+var ErrMarkup = errors.New("unexpected markup")
+
+// requiredAttrs are data attributes every flight button must have.
+var requiredAttrs = []string{
+	"data-flight-type",
+	"data-flight-number",
+	"data-flight-date",
+	"data-flight-time-planed",
 }
 
 // parse gets url and parses it for flights data.
@@ -73,52 +138,124 @@ func parse(url string) ([]Flight, error) {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: %s", url, resp.Status)
+	}
 
-	doc, err := html.Parse(resp.Body)
+	flights, err := parseFlights(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", url, err)
+	}
+	return flights, nil
+}
+
+// parseFlights parses HTML page for flights data. It returns error wrapping
+// ErrMarkup if the page contains no flights.
+func parseFlights(r io.Reader) ([]Flight, error) {
+	doc, err := html.Parse(r)
 	if err != nil {
 		return nil, err
 	}
-	flights := visit(nil, doc)
+	flights, err := visit(nil, doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(flights) == 0 {
+		return nil, fmt.Errorf("%w: no flight buttons found", ErrMarkup)
+	}
 	return flights, nil
 }
 
 // visit traverses an HTML node tree, extracts and returns data about flights.
-func visit(flights []Flight, n *html.Node) []Flight {
+// Flights are buttons with data-flight-* attributes.
+func visit(flights []Flight, n *html.Node) ([]Flight, error) {
 	if n.Type == html.ElementNode && n.Data == "button" {
-		var flight Flight
+		attrs := make(map[string]string)
+		var isFlight bool
 		for _, a := range n.Attr {
-			switch a.Key {
-			case "data-flight-type":
-				if a.Val == "arrival" {
-					flight.Type = Arrival
-				} else {
-					flight.Type = Departure
-				}
-			case "data-destination":
-				flight.Destination = a.Val
-			case "data-flight-number":
-				flight.Number = a.Val
-			case "data-flight-date":
-				t, _ := time.Parse("02. 01. 2006", a.Val)
-				flight.Date = t
-			case "data-flight-time-planed":
-				t, _ := time.Parse("15:04", a.Val)
-				flight.TimePlanned = t
-			case "data-flight-time-current":
-				t, _ := time.Parse("15:04", a.Val)
-				flight.TimeCurrent = t
-			case "data-airline":
-				flight.Airline = a.Val
-			case "data-flight-airplane":
-				flight.Airplane = a.Val
+			attrs[a.Key] = a.Val
+			if strings.HasPrefix(a.Key, "data-flight-") {
+				isFlight = true
 			}
 		}
-		if !flight.Date.IsZero() {
+		if isFlight {
+			flight, err := newFlight(attrs)
+			if err != nil {
+				return nil, err
+			}
 			flights = append(flights, flight)
 		}
 	}
 	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		flights = visit(flights, c)
+		var err error
+		flights, err = visit(flights, c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return flights, nil
+}
+
+// newFlight returns flight described by data attributes of a button.
+func newFlight(attrs map[string]string) (Flight, error) {
+	for _, attr := range requiredAttrs {
+		if _, ok := attrs[attr]; !ok {
+			return Flight{}, fmt.Errorf("%w: flight %s: missing %s attribute", ErrMarkup, attrs["data-flight-number"], attr)
+		}
+	}
+	switch t := attrs["data-flight-type"]; t {
+	case "arrival", "departure":
+	default:
+		return Flight{}, fmt.Errorf("%w: flight %s: unknown type %q", ErrMarkup, attrs["data-flight-number"], t)
+	}
+
+	flight := Flight{
+		Type:        Departure,
+		Number:      attrs["data-flight-number"],
+		Destination: attrs["data-destination"],
+		Airline:     attrs["data-airline"],
+		Airplane:    attrs["data-flight-airplane"],
+	}
+	if attrs["data-flight-type"] == "arrival" {
+		flight.Type = Arrival
+	}
+
+	var err error
+	flight.Date, err = time.ParseInLocation("02. 01. 2006", attrs["data-flight-date"], Location)
+	if err != nil {
+		return Flight{}, fmt.Errorf("flight %s: parsing date: %v", flight.Number, err)
+	}
+	flight.TimePlanned, err = clockTime(flight.Date, attrs["data-flight-time-planed"])
+	if err != nil {
+		return Flight{}, fmt.Errorf("flight %s: parsing planned time: %v", flight.Number, err)
+	}
+	flight.TimeCurrent, err = clockTime(flight.Date, attrs["data-flight-time-current"])
+	if err != nil {
+		return Flight{}, fmt.Errorf("flight %s: parsing current time: %v", flight.Number, err)
+	}
+
+	// Only clock is shown so the current time may be on the previous or the
+	// next day, e.g. planned at 23:50 and delayed to 00:20.
+	if !flight.TimePlanned.IsZero() && !flight.TimeCurrent.IsZero() {
+		switch d := flight.TimeCurrent.Sub(flight.TimePlanned); {
+		case d < -12*time.Hour:
+			flight.TimeCurrent = flight.TimeCurrent.AddDate(0, 0, 1)
+		case d > 12*time.Hour:
+			flight.TimeCurrent = flight.TimeCurrent.AddDate(0, 0, -1)
+		}
+	}
+	return flight, nil
+}
+
+// clockTime returns time on date at clock like 15:04. Empty clock means zero
+// time.
+func clockTime(date time.Time, clock string) (time.Time, error) {
+	if clock == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, err
 	}
-	return flights
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, date.Location()), nil
 }
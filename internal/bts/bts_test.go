@@ -0,0 +1,134 @@
+package bts
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// buttonAttrs returns data attributes of a flight button with date and times
+// from attrs.
+func buttonAttrs(attrs map[string]string) map[string]string {
+	all := map[string]string{
+		"data-flight-type":        "arrival",
+		"data-flight-number":      "FR 42",
+		"data-flight-time-planed": "",
+	}
+	for k, v := range attrs {
+		all[k] = v
+	}
+	return all
+}
+
+func TestNewFlight(t *testing.T) {
+	tests := []struct {
+		name        string
+		attrs       map[string]string
+		wantPlanned time.Time
+		wantCurrent time.Time
+		wantDelay   time.Duration
+	}{
+		{
+			name: "on time",
+			attrs: map[string]string{
+				"data-flight-date":        "12. 06. 2023",
+				"data-flight-time-planed": "08:32",
+			},
+			wantPlanned: parseTime("2023-06-12 08:32"),
+		},
+		{
+			name: "delayed",
+			attrs: map[string]string{
+				"data-flight-date":         "12. 06. 2023",
+				"data-flight-time-planed":  "08:32",
+				"data-flight-time-current": "09:02",
+			},
+			wantPlanned: parseTime("2023-06-12 08:32"),
+			wantCurrent: parseTime("2023-06-12 09:02"),
+			wantDelay:   30 * time.Minute,
+		},
+		{
+			name: "delayed after midnight",
+			attrs: map[string]string{
+				"data-flight-date":         "12. 06. 2023",
+				"data-flight-time-planed":  "23:50",
+				"data-flight-time-current": "00:20",
+			},
+			wantPlanned: parseTime("2023-06-12 23:50"),
+			wantCurrent: parseTime("2023-06-13 00:20"),
+			wantDelay:   30 * time.Minute,
+		},
+		{
+			name: "early before midnight",
+			attrs: map[string]string{
+				"data-flight-date":         "13. 06. 2023",
+				"data-flight-time-planed":  "00:05",
+				"data-flight-time-current": "23:55",
+			},
+			wantPlanned: parseTime("2023-06-13 00:05"),
+			wantCurrent: parseTime("2023-06-12 23:55"),
+			wantDelay:   -10 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newFlight(buttonAttrs(tt.attrs))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !f.TimePlanned.Equal(tt.wantPlanned) {
+				t.Errorf("TimePlanned = %v, want %v", f.TimePlanned, tt.wantPlanned)
+			}
+			if !f.TimeCurrent.Equal(tt.wantCurrent) {
+				t.Errorf("TimeCurrent = %v, want %v", f.TimeCurrent, tt.wantCurrent)
+			}
+			if f.Delay() != tt.wantDelay {
+				t.Errorf("Delay() = %v, want %v", f.Delay(), tt.wantDelay)
+			}
+			if f.TimePlanned.Location() != Location {
+				t.Errorf("TimePlanned is in %v, want %v", f.TimePlanned.Location(), Location)
+			}
+		})
+	}
+}
+
+func TestNewFlightFailsOnBadTime(t *testing.T) {
+	for _, attrs := range []map[string]string{
+		{"data-flight-date": "2023-06-12"},
+		{"data-flight-date": "12. 06. 2023", "data-flight-time-planed": "8.32"},
+		{"data-flight-date": "12. 06. 2023", "data-flight-time-current": "25:00"},
+	} {
+		if _, err := newFlight(buttonAttrs(attrs)); err == nil {
+			t.Errorf("newFlight(%v) returned nil error", attrs)
+		}
+	}
+}
+
+func TestNewFlightFailsOnUnknownMarkup(t *testing.T) {
+	for _, attrs := range []map[string]string{
+		{"data-flight-number": "FR 42", "data-flight-date": "12. 06. 2023"},
+		{"data-flight-type": "transit", "data-flight-number": "FR 42", "data-flight-date": "12. 06. 2023", "data-flight-time-planed": "08:32"},
+	} {
+		if _, err := newFlight(attrs); !errors.Is(err, ErrMarkup) {
+			t.Errorf("newFlight(%v) = %v, want ErrMarkup", attrs, err)
+		}
+	}
+}
+
+func TestFormatDelay(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, ""},
+		{20 * time.Second, ""},
+		{25 * time.Minute, "+25m"},
+		{-10 * time.Minute, "-10m"},
+		{65 * time.Minute, "+1h5m"},
+	}
+	for _, tt := range tests {
+		if got := formatDelay(tt.d); got != tt.want {
+			t.Errorf("formatDelay(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
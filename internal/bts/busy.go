@@ -1,30 +1,122 @@
 package bts
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
 
-// Busy returns flights happenning during the most busy hour.
-func (flights Flights) Busy() Flights {
-	type DateHour string
-
-	count := make(map[DateHour]Flights)
-	for _, flight := range flights {
-		d := flight.Date.Format("2006-01-02")
-		h := fmt.Sprintf("%d", flight.TimePlanned.Hour())
-		if !flight.TimeCurrent.IsZero() { // planned time changed (delay)
-			h = fmt.Sprintf("%d", flight.TimeCurrent.Hour())
+// Bucket holds flights happening within a time window [Start, End).
+type Bucket struct {
+	Start   time.Time
+	End     time.Time
+	Flights Flights
+}
+
+// Arrivals returns the number of arriving flights in b.
+func (b Bucket) Arrivals() int {
+	return len(b.Flights.Filter(func(f Flight) bool { return f.Type == Arrival }))
+}
+
+// Departures returns the number of departing flights in b.
+func (b Bucket) Departures() int {
+	return len(b.Flights.Filter(func(f Flight) bool { return f.Type == Departure }))
+}
+
+// Histogram returns buckets of size starting every step from the first to the
+// last flight. Buckets don't overlap when step equals size and are sliding
+// windows when step is smaller. Flights are counted at their current time if
+// known. Bucket starts are aligned to step in flights' time zone.
+func (flights Flights) Histogram(size, step time.Duration) []Bucket {
+	if len(flights) == 0 || size <= 0 || step <= 0 {
+		return nil
+	}
+	first, last := flights[0].Time(), flights[0].Time()
+	for _, f := range flights {
+		if f.Time().Before(first) {
+			first = f.Time()
+		}
+		if f.Time().After(last) {
+			last = f.Time()
 		}
-		var dateHour DateHour = DateHour(d + h)
-		count[dateHour] = append(count[dateHour], flight)
 	}
 
-	var maxFlights int
-	var mostBusyDateHour DateHour
-	for dh, flights := range count {
-		if len(flights) > maxFlights {
-			maxFlights = len(flights)
-			mostBusyDateHour = dh
+	// Start with the first window containing the first flight.
+	start := alignTo(first, step)
+	for start.Add(-step).Add(size).After(first) {
+		start = start.Add(-step)
+	}
+
+	var buckets []Bucket
+	for ; !start.After(last); start = start.Add(step) {
+		b := Bucket{Start: start, End: start.Add(size)}
+		b.Flights = flights.Filter(func(f Flight) bool {
+			return !f.Time().Before(b.Start) && f.Time().Before(b.End)
+		})
+		buckets = append(buckets, b)
+	}
+	return buckets
+}
+
+// alignTo returns t rounded down to a multiple of d since midnight of t's
+// day in t's location.
+func alignTo(t time.Time, d time.Duration) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return midnight.Add(t.Sub(midnight) / d * d)
+}
+
+// Busiest returns the bucket with the most flights. Ties are broken by
+// taking the earliest bucket.
+func (flights Flights) Busiest(size, step time.Duration) Bucket {
+	var busiest Bucket
+	for _, b := range flights.Histogram(size, step) {
+		if len(b.Flights) > len(busiest.Flights) {
+			busiest = b
 		}
 	}
+	return busiest
+}
 
-	return count[mostBusyDateHour]
+// Busy returns flights happenning during the most busy hour.
+func (flights Flights) Busy() Flights {
+	return flights.Busiest(time.Hour, time.Hour).Flights
 }
+
+// histogramWidth is the maximum length of a bar.
+const histogramWidth = 50
+
+// PrintHistogram prints buckets as a bar chart with arrivals and departures
+// to w.
+func PrintHistogram(w io.Writer, buckets []Bucket) error {
+	var most int
+	for _, b := range buckets {
+		most = max(most, len(b.Flights))
+	}
+	scale := 1.0
+	if most > histogramWidth {
+		scale = float64(histogramWidth) / float64(most)
+	}
+
+	layout := "15:04"
+	if len(buckets) > 0 && buckets[0].Start.YearDay() != buckets[len(buckets)-1].End.Add(-time.Nanosecond).YearDay() {
+		layout = "2006-01-02 15:04"
+	}
+	if _, err := fmt.Fprintf(w, "%s arrivals, %s departures\n", arrivalBar, departureBar); err != nil {
+		return err
+	}
+	for _, b := range buckets {
+		arr, dep := b.Arrivals(), b.Departures()
+		bar := strings.Repeat(arrivalBar, int(float64(arr)*scale+0.5)) +
+			strings.Repeat(departureBar, int(float64(dep)*scale+0.5))
+		if _, err := fmt.Fprintf(w, "%s-%s %s %d\n", b.Start.Format(layout), b.End.Format("15:04"), bar, arr+dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	arrivalBar   = "█"
+	departureBar = "░"
+)
@@ -1,20 +1,22 @@
 package bts
 
 import (
+	"bytes"
+	"fmt"
 	"log"
 	"testing"
 	"time"
 )
 
 func parseDate(s string) time.Time {
-	t, err := time.Parse("2006-01-02", s)
+	t, err := time.ParseInLocation("2006-01-02", s, Location)
 	if err != nil {
 		log.Fatalf("parsing date: %v", err)
 	}
 	return t
 }
 func parseTime(s string) time.Time {
-	t, err := time.Parse("15:04", s)
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, Location)
 	if err != nil {
 		log.Fatalf("parsing time: %v", err)
 	}
@@ -37,7 +39,7 @@ func TestFlights_Busy(t *testing.T) {
 			flights: []Flight{
 				{
 					Date:        parseDate("2023-06-12"),
-					TimePlanned: parseTime("08:32"),
+					TimePlanned: parseTime("2023-06-12 08:32"),
 				},
 			},
 			want: 1,
@@ -47,15 +49,34 @@ func TestFlights_Busy(t *testing.T) {
 			flights: []Flight{
 				{
 					Date:        parseDate("2023-06-12"),
-					TimePlanned: parseTime("08:32"),
+					TimePlanned: parseTime("2023-06-12 08:32"),
 				},
 				{
 					Date:        parseDate("2023-06-12"),
-					TimePlanned: parseTime("08:42"),
+					TimePlanned: parseTime("2023-06-12 08:42"),
 				},
 				{
 					Date:        parseDate("2023-06-13"),
-					TimePlanned: parseTime("08:32"),
+					TimePlanned: parseTime("2023-06-13 08:32"),
+				},
+			},
+			want: 2,
+		},
+		{
+			name: "delayed flight counts in current hour",
+			flights: []Flight{
+				{
+					Date:        parseDate("2023-06-12"),
+					TimePlanned: parseTime("2023-06-12 08:32"),
+					TimeCurrent: parseTime("2023-06-12 09:10"),
+				},
+				{
+					Date:        parseDate("2023-06-12"),
+					TimePlanned: parseTime("2023-06-12 09:05"),
+				},
+				{
+					Date:        parseDate("2023-06-12"),
+					TimePlanned: parseTime("2023-06-12 08:40"),
 				},
 			},
 			want: 2,
@@ -69,3 +90,79 @@ func TestFlights_Busy(t *testing.T) {
 		})
 	}
 }
+
+func TestFlights_BusyBreaksTiesByTakingEarliestHour(t *testing.T) {
+	flights := Flights{
+		{Number: "late", TimePlanned: parseTime("2023-06-12 10:10")},
+		{Number: "early", TimePlanned: parseTime("2023-06-12 08:10")},
+	}
+	for i := 0; i < 10; i++ {
+		if got := flights.Busy(); len(got) != 1 || got[0].Number != "early" {
+			t.Fatalf("Flights.Busy() = %v, want the early flight", got)
+		}
+	}
+}
+
+func TestFlights_Histogram(t *testing.T) {
+	flights := Flights{
+		{Type: Arrival, TimePlanned: parseTime("2023-06-12 08:05")},
+		{Type: Departure, TimePlanned: parseTime("2023-06-12 08:20")},
+		{Type: Departure, TimePlanned: parseTime("2023-06-12 08:35"), TimeCurrent: parseTime("2023-06-12 09:05")},
+	}
+	type bucket struct {
+		start, end           string
+		arrivals, departures int
+	}
+	tests := []struct {
+		name       string
+		size, step time.Duration
+		want       []bucket
+	}{
+		{
+			name: "hours",
+			size: time.Hour, step: time.Hour,
+			want: []bucket{{"08:00", "09:00", 1, 1}, {"09:00", "10:00", 0, 1}},
+		},
+		{
+			name: "half hours",
+			size: 30 * time.Minute, step: 30 * time.Minute,
+			want: []bucket{{"08:00", "08:30", 1, 1}, {"08:30", "09:00", 0, 0}, {"09:00", "09:30", 0, 1}},
+		},
+		{
+			name: "sliding hour every 30 minutes",
+			size: time.Hour, step: 30 * time.Minute,
+			want: []bucket{{"07:30", "08:30", 1, 1}, {"08:00", "09:00", 1, 1}, {"08:30", "09:30", 0, 1}, {"09:00", "10:00", 0, 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []bucket
+			for _, b := range flights.Histogram(tt.size, tt.step) {
+				got = append(got, bucket{b.Start.Format("15:04"), b.End.Format("15:04"), b.Arrivals(), b.Departures()})
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Histogram() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintHistogram(t *testing.T) {
+	flights := Flights{
+		{Type: Arrival, TimePlanned: parseTime("2023-06-12 08:05")},
+		{Type: Departure, TimePlanned: parseTime("2023-06-12 08:20")},
+		{Type: Departure, TimePlanned: parseTime("2023-06-12 10:20")},
+	}
+	var buf bytes.Buffer
+	if err := PrintHistogram(&buf, flights.Histogram(time.Hour, time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	want := `█ arrivals, ░ departures
+08:00-09:00 █░ 2
+09:00-10:00  0
+10:00-11:00 ░ 1
+`
+	if got := buf.String(); got != want {
+		t.Errorf("PrintHistogram() printed\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,167 @@
+package bts
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// flightJSON is a flight as exported to JSON.
+type flightJSON struct {
+	Type         string     `json:"type"`
+	Number       string     `json:"number"`
+	Destination  string     `json:"destination"`
+	Date         string     `json:"date"`
+	Planned      time.Time  `json:"planned"`
+	Current      *time.Time `json:"current,omitempty"`
+	DelayMinutes int        `json:"delayMinutes"`
+	Airline      string     `json:"airline"`
+	Airplane     string     `json:"airplane"`
+}
+
+func (f Flight) MarshalJSON() ([]byte, error) {
+	fj := flightJSON{
+		Type:         f.Type.String(),
+		Number:       f.Number,
+		Destination:  f.Destination,
+		Date:         f.Date.Format("2006-01-02"),
+		Planned:      f.TimePlanned,
+		DelayMinutes: int(f.Delay().Minutes()),
+		Airline:      f.Airline,
+		Airplane:     f.Airplane,
+	}
+	if !f.TimeCurrent.IsZero() {
+		fj.Current = &f.TimeCurrent
+	}
+	return json.Marshal(fj)
+}
+
+// UnmarshalJSON parses flight as written by MarshalJSON. Times are converted
+// to Location.
+func (f *Flight) UnmarshalJSON(data []byte) error {
+	var fj flightJSON
+	if err := json.Unmarshal(data, &fj); err != nil {
+		return err
+	}
+	date, err := time.ParseInLocation("2006-01-02", fj.Date, Location)
+	if err != nil {
+		return err
+	}
+	*f = Flight{
+		Type:        Departure,
+		Number:      fj.Number,
+		Destination: fj.Destination,
+		Date:        date,
+		TimePlanned: fj.Planned.In(Location),
+		Airline:     fj.Airline,
+		Airplane:    fj.Airplane,
+	}
+	if fj.Type == Arrival.String() {
+		f.Type = Arrival
+	}
+	if fj.Current != nil {
+		f.TimeCurrent = fj.Current.In(Location)
+	}
+	return nil
+}
+
+// WriteJSON writes flights to w as JSON array.
+func (flights Flights) WriteJSON(w io.Writer) error {
+	if flights == nil {
+		flights = Flights{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(flights)
+}
+
+// WriteCSV writes flights to w as CSV with header. Times are in RFC 3339
+// format.
+func (flights Flights) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"type", "number", "destination", "date", "planned", "current", "delay_minutes", "airline", "airplane"})
+	for _, f := range flights {
+		var current string
+		if !f.TimeCurrent.IsZero() {
+			current = f.TimeCurrent.Format(time.RFC3339)
+		}
+		cw.Write([]string{
+			f.Type.String(), f.Number, f.Destination,
+			f.Date.Format("2006-01-02"),
+			f.TimePlanned.Format(time.RFC3339),
+			current,
+			fmt.Sprint(int(f.Delay().Minutes())),
+			f.Airline, f.Airplane,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteICal writes flights to w as iCalendar with an event per flight
+// starting at the flight's current time, or planned if not known.
+func (flights Flights) WriteICal(w io.Writer) error {
+	const utc = "20060102T150405Z"
+	var lines []string
+	lines = append(lines, "BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//jreisinger//bts//EN")
+	now := time.Now().UTC().Format(utc)
+	for _, f := range flights {
+		summary := fmt.Sprintf("Departure %s to %s", f.Number, f.Destination)
+		if f.Type == Arrival {
+			summary = fmt.Sprintf("Arrival %s from %s", f.Number, f.Destination)
+		}
+		description := fmt.Sprintf("Planned %s", clock(f.TimePlanned))
+		if !f.TimeCurrent.IsZero() {
+			description += fmt.Sprintf(", current %s", clock(f.TimeCurrent))
+			if d := formatDelay(f.Delay()); d != "" {
+				description += fmt.Sprintf(" (%s)", d)
+			}
+		}
+		description += fmt.Sprintf(".\nAirline %s, airplane %s.", f.Airline, f.Airplane)
+
+		uid := fmt.Sprintf("%s-%s-%s@bts", f.Type, strings.ReplaceAll(f.Number, " ", ""), f.Date.Format("20060102"))
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+uid,
+			"DTSTAMP:"+now,
+			"DTSTART:"+f.Time().UTC().Format(utc),
+			"SUMMARY:"+icalEscape(summary),
+			"DESCRIPTION:"+icalEscape(description),
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, icalFold(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// icalEscape escapes iCalendar text value.
+func icalEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// icalFold folds line longer than 75 octets into continuation lines starting
+// with space. It doesn't split UTF-8 characters.
+func icalFold(line string) string {
+	const maxLen = 75
+	var b strings.Builder
+	n := 0
+	for _, r := range line {
+		size := len(string(r))
+		if n+size > maxLen {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
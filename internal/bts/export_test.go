@@ -0,0 +1,92 @@
+package bts
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var exportFlights = Flights{
+	{Type: Arrival, Number: "W6 5061", Destination: "London, Luton", Date: parseDate("2023-06-12"),
+		TimePlanned: parseTime("2023-06-12 08:00"), TimeCurrent: parseTime("2023-06-12 08:30"),
+		Airline: "Wizz Air", Airplane: "A321"},
+	{Type: Departure, Number: "FR 42", Destination: "Paris", Date: parseDate("2023-06-12"),
+		TimePlanned: parseTime("2023-06-12 10:00"), Airline: "Ryanair", Airplane: "B738"},
+}
+
+func TestFlightsPrint(t *testing.T) {
+	var buf bytes.Buffer
+	if err := exportFlights.Print(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `Type       Number   Destination    Date        Planned  Current  Delay  Airline   Airplane
+----       ------   -----------    ----        -------  -------  -----  -------   --------
+arrival    W6 5061  London, Luton  2023-06-12  08:00    08:30    +30m   Wizz Air  A321
+departure  FR 42    Paris          2023-06-12  10:00                    Ryanair   B738
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Print() printed\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFlightsWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := exportFlights[1:].WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `[
+  {
+    "type": "departure",
+    "number": "FR 42",
+    "destination": "Paris",
+    "date": "2023-06-12",
+    "planned": "2023-06-12T10:00:00+02:00",
+    "delayMinutes": 0,
+    "airline": "Ryanair",
+    "airplane": "B738"
+  }
+]
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteJSON() wrote\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFlightsWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := exportFlights.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `type,number,destination,date,planned,current,delay_minutes,airline,airplane
+arrival,W6 5061,"London, Luton",2023-06-12,2023-06-12T08:00:00+02:00,2023-06-12T08:30:00+02:00,30,Wizz Air,A321
+departure,FR 42,Paris,2023-06-12,2023-06-12T10:00:00+02:00,,0,Ryanair,B738
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCSV() wrote\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFlightsWriteICal(t *testing.T) {
+	var buf bytes.Buffer
+	if err := exportFlights[:1].WriteICal(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := regexp.MustCompile(`DTSTAMP:\d{8}T\d{6}Z`).ReplaceAllString(buf.String(), "DTSTAMP:X")
+	want := strings.ReplaceAll(`BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//jreisinger//bts//EN
+BEGIN:VEVENT
+UID:arrival-W65061-20230612@bts
+DTSTAMP:X
+DTSTART:20230612T063000Z
+SUMMARY:Arrival W6 5061 from London\, Luton
+DESCRIPTION:Planned 08:00\, current 08:30 (+30m).\nAirline Wizz Air\, airpl
+ ane A321.
+END:VEVENT
+END:VCALENDAR
+`, "\n", "\r\n")
+	if got != want {
+		t.Errorf("WriteICal() wrote\n%q\nwant\n%q", got, want)
+	}
+}
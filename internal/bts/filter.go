@@ -0,0 +1,88 @@
+package bts
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Filter returns flights for which keep returns true. Other filtering
+// methods are built on it and can be chained like
+//
+//	flights.Airline("wizz").Between(14*time.Hour, 18*time.Hour).Delayed(0)
+func (flights Flights) Filter(keep func(Flight) bool) Flights {
+	var kept Flights
+	for _, f := range flights {
+		if keep(f) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// Destination returns flights whose destination contains s, ignoring case.
+func (flights Flights) Destination(s string) Flights {
+	return flights.Filter(func(f Flight) bool { return containsFold(f.Destination, s) })
+}
+
+// DestinationMatching returns flights whose destination matches re.
+func (flights Flights) DestinationMatching(re *regexp.Regexp) Flights {
+	return flights.Filter(func(f Flight) bool { return re.MatchString(f.Destination) })
+}
+
+// Airline returns flights whose airline contains s, ignoring case.
+func (flights Flights) Airline(s string) Flights {
+	return flights.Filter(func(f Flight) bool { return containsFold(f.Airline, s) })
+}
+
+// Number returns flights with number, ignoring case and spaces, so "w6 5061"
+// matches W65061.
+func (flights Flights) Number(number string) Flights {
+	return flights.Filter(func(f Flight) bool { return normalizeNumber(f.Number) == normalizeNumber(number) })
+}
+
+// On returns flights planned on the same day as date.
+func (flights Flights) On(date time.Time) Flights {
+	y, m, d := date.Date()
+	return flights.Filter(func(f Flight) bool {
+		fy, fm, fd := f.Date.Date()
+		return fy == y && fm == m && fd == d
+	})
+}
+
+// Between returns flights whose time of day, current if known, is within
+// from and to, both since midnight and inclusive. If from is after to, the
+// window spans midnight.
+func (flights Flights) Between(from, to time.Duration) Flights {
+	return flights.Filter(func(f Flight) bool {
+		t := f.Time()
+		sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		if from <= to {
+			return sinceMidnight >= from && sinceMidnight <= to
+		}
+		return sinceMidnight >= from || sinceMidnight <= to
+	})
+}
+
+// Delayed returns flights delayed more than d.
+func (flights Flights) Delayed(d time.Duration) Flights {
+	return flights.Filter(func(f Flight) bool { return f.Delay() > d })
+}
+
+// ParseClock parses time of day like 14:00 and returns time since midnight.
+func ParseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("parsing clock %q: want HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func normalizeNumber(number string) string {
+	return strings.ToUpper(strings.ReplaceAll(number, " ", ""))
+}
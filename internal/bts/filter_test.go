@@ -0,0 +1,63 @@
+package bts
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var filterFlights = Flights{
+	{Number: "W6 5061", Destination: "London Luton", Airline: "Wizz Air", Date: parseDate("2023-06-12"),
+		TimePlanned: parseTime("2023-06-12 06:00")},
+	{Number: "FR 1234", Destination: "London Stansted", Airline: "Ryanair", Date: parseDate("2023-06-12"),
+		TimePlanned: parseTime("2023-06-12 14:30"), TimeCurrent: parseTime("2023-06-12 15:10")},
+	{Number: "FR 42", Destination: "Paris Beauvais", Airline: "Ryanair", Date: parseDate("2023-06-13"),
+		TimePlanned: parseTime("2023-06-13 23:50"), TimeCurrent: parseTime("2023-06-14 00:05")},
+}
+
+func numbers(flights Flights) string {
+	var ns []string
+	for _, f := range flights {
+		ns = append(ns, f.Number)
+	}
+	return strings.Join(ns, ",")
+}
+
+func TestFlightsFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Flights
+		want string
+	}{
+		{"destination", filterFlights.Destination("london"), "W6 5061,FR 1234"},
+		{"destination regexp", filterFlights.DestinationMatching(regexp.MustCompile(`^(Paris|Rome)`)), "FR 42"},
+		{"airline", filterFlights.Airline("RYAN"), "FR 1234,FR 42"},
+		{"number", filterFlights.Number("w65061"), "W6 5061"},
+		{"on date", filterFlights.On(parseDate("2023-06-13")), "FR 42"},
+		{"between", filterFlights.Between(14*time.Hour, 18*time.Hour), "FR 1234"},
+		{"between over midnight", filterFlights.Between(22*time.Hour, 7*time.Hour), "W6 5061,FR 42"},
+		{"delayed", filterFlights.Delayed(0), "FR 1234,FR 42"},
+		{"delayed more than 30m", filterFlights.Delayed(30 * time.Minute), "FR 1234"},
+		{"chained", filterFlights.Airline("ryanair").Destination("london").Delayed(0), "FR 1234"},
+		{"none", filterFlights.Airline("lufthansa"), ""},
+	}
+	for _, tt := range tests {
+		if got := numbers(tt.got); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	got, err := ParseClock("14:05")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 14*time.Hour + 5*time.Minute; got != want {
+		t.Errorf("ParseClock(14:05) = %v, want %v", got, want)
+	}
+	if _, err := ParseClock("2pm"); err == nil {
+		t.Error("ParseClock(2pm) returned nil error")
+	}
+}
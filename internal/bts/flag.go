@@ -2,24 +2,13 @@ package bts
 
 import (
 	"flag"
-	"fmt"
+	"strings"
 )
 
 type flightTypeFlag struct{ FlightType }
 
 func (f *flightTypeFlag) Set(s string) error {
-	switch s {
-	case "arrival":
-		f.FlightType = Arrival
-		return nil
-	case "departure":
-		f.FlightType = Departure
-		return nil
-	case "both":
-		f.FlightType = Both
-		return nil
-	}
-	return fmt.Errorf("use arrival, departure or both")
+	return f.UnmarshalText([]byte(s))
 }
 
 // FlightTypeFlag defines flights type flag with the specified name, default
@@ -29,3 +18,32 @@ func FlightTypeFlag(name string, value FlightType, usage string) *FlightType {
 	flag.CommandLine.Var(&f, name, usage)
 	return &f.FlightType
 }
+
+type airportFlag struct {
+	code     string
+	provider *Provider
+}
+
+func (f *airportFlag) String() string { return f.code }
+
+func (f *airportFlag) Set(s string) error {
+	p, err := LookupAirport(s)
+	if err != nil {
+		return err
+	}
+	f.code = strings.ToLower(s)
+	*f.provider = p
+	return nil
+}
+
+// AirportFlag defines airport flag with the specified name, default IATA
+// code, and usage, and returns address of the airport's provider.
+func AirportFlag(name string, code string, usage string) *Provider {
+	p, err := LookupAirport(code)
+	if err != nil {
+		panic(err)
+	}
+	f := airportFlag{code: code, provider: &p}
+	flag.CommandLine.Var(&f, name, usage)
+	return f.provider
+}
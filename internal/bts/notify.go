@@ -0,0 +1,75 @@
+package bts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"time"
+)
+
+// PrintNotifier returns notifier writing events to w, one per line.
+func PrintNotifier(w io.Writer) func(Event) error {
+	return func(e Event) error {
+		_, err := fmt.Fprintf(w, "%s %s\n", time.Now().Format("15:04:05"), e)
+		return err
+	}
+}
+
+// CommandNotifier returns notifier running command name with args followed
+// by event description, e.g. notify-send.
+func CommandNotifier(name string, args ...string) func(Event) error {
+	return func(e Event) error {
+		cmd := exec.Command(name, append(args, e.String())...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%v: %s", err, bytes.TrimSpace(out))
+		}
+		return nil
+	}
+}
+
+// webhookPayload is JSON sent by WebhookNotifier.
+type webhookPayload struct {
+	Event        string     `json:"event"`
+	Message      string     `json:"message"`
+	Type         string     `json:"type"`
+	Number       string     `json:"number"`
+	Destination  string     `json:"destination"`
+	Planned      time.Time  `json:"planned"`
+	Current      *time.Time `json:"current,omitempty"`
+	DelayMinutes int        `json:"delayMinutes"`
+}
+
+// WebhookNotifier returns notifier POSTing events as JSON to url.
+func WebhookNotifier(url string) func(Event) error {
+	return func(e Event) error {
+		f := e.Flight
+		payload := webhookPayload{
+			Event:        e.Type.String(),
+			Message:      e.String(),
+			Type:         f.Type.String(),
+			Number:       f.Number,
+			Destination:  f.Destination,
+			Planned:      f.TimePlanned,
+			DelayMinutes: int(f.Delay().Minutes()),
+		}
+		if !f.TimeCurrent.IsZero() {
+			payload.Current = &f.TimeCurrent
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("webhook: %s", resp.Status)
+		}
+		return nil
+	}
+}
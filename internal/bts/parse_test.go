@@ -0,0 +1,142 @@
+package bts
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+var (
+	capture = flag.Bool("capture", false, "download live arrivals and departures pages into testdata")
+	update  = flag.Bool("update", false, "rewrite golden files in testdata with parsed flights")
+)
+
+// Pages in testdata are hand-written stand-ins having the attributes the
+// parser reads; they are not captures of the live website yet. To replace
+// them with live pages, trimmed to a few flights if needed, run
+//
+//	go test -run TestParseFlights -capture -update
+//
+// and review the diff of the golden files.
+var pages = []struct {
+	file, url string
+}{
+	{"testdata/arrivals.html", ArrivalsURL},
+	{"testdata/departures.html", DeparturesURL},
+}
+
+func parseFile(t *testing.T, file string) (Flights, error) {
+	t.Helper()
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	return parseFlights(f)
+}
+
+// capturePage saves page at url to file.
+func capturePage(t *testing.T, url, file string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("get %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseFlights(t *testing.T) {
+	for _, page := range pages {
+		t.Run(page.file, func(t *testing.T) {
+			if *capture {
+				capturePage(t, page.url, page.file)
+			}
+			flights, err := parseFile(t, page.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range flights {
+				if f.Number == "" || f.Destination == "" || f.TimePlanned.IsZero() {
+					t.Errorf("incomplete flight %+v", f)
+				}
+			}
+
+			var got bytes.Buffer
+			if err := flights.WriteJSON(&got); err != nil {
+				t.Fatal(err)
+			}
+			golden := strings.TrimSuffix(page.file, ".html") + ".json"
+			if *update {
+				if err := os.WriteFile(golden, got.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != string(want) {
+				t.Errorf("parsed flights differ from %s:\n%s", golden, got.String())
+			}
+		})
+	}
+}
+
+func TestParseFlightsDetectsChangedMarkup(t *testing.T) {
+	for _, file := range []string{
+		"testdata/no-flights.html",
+		"testdata/renamed-attributes.html",
+	} {
+		if _, err := parseFile(t, file); !errors.Is(err, ErrMarkup) {
+			t.Errorf("%s: got error %v, want ErrMarkup", file, err)
+		}
+	}
+}
+
+func TestGetFlightsFromBTS(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/arrivals", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/arrivals.html")
+	})
+	mux.HandleFunc("/departures", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/departures.html")
+	})
+	mux.HandleFunc("/changed", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/no-flights.html")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	flights, err := GetFlights(BTS{ArrivalsURL: srv.URL + "/arrivals", DeparturesURL: srv.URL + "/departures"}, Both)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flights) != 4 {
+		t.Errorf("got %d flights, want 4", len(flights))
+	}
+
+	_, err = GetFlights(BTS{ArrivalsURL: srv.URL + "/changed"}, Arrival)
+	if !errors.Is(err, ErrMarkup) {
+		t.Errorf("got error %v, want ErrMarkup", err)
+	}
+	_, err = GetFlights(BTS{ArrivalsURL: srv.URL + "/missing"}, Arrival)
+	if err == nil || errors.Is(err, ErrMarkup) {
+		t.Errorf("got error %v for missing page, want HTTP error", err)
+	}
+}
@@ -2,22 +2,46 @@ package bts
 
 import (
 	"fmt"
-	"os"
+	"io"
 	"text/tabwriter"
+	"time"
 )
 
-// Print prints a table with flights.
-func (flights Flights) Print() {
-	const format = "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
-	tw := new(tabwriter.Writer).Init(os.Stdout, 0, 8, 2, ' ', 0)
-	fmt.Fprintf(tw, format, "Type", "Number", "Destination", "Date", "Planned", "Current", "Airline", "Airplane")
-	fmt.Fprintf(tw, format, "----", "------", "-----------", "----", "-------", "-------", "-------", "--------")
+// Print prints a table with flights to w.
+func (flights Flights) Print(w io.Writer) error {
+	const format = "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n"
+	tw := new(tabwriter.Writer).Init(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, format, "Type", "Number", "Destination", "Date", "Planned", "Current", "Delay", "Airline", "Airplane")
+	fmt.Fprintf(tw, format, "----", "------", "-----------", "----", "-------", "-------", "-----", "-------", "--------")
 	for _, f := range flights {
 		fmt.Fprintf(tw, format, f.Type, f.Number, f.Destination,
 			f.Date.Format("2006-01-02"),
-			f.TimePlanned.Format("15:04"),
-			f.TimeCurrent.Format("15:04"),
+			clock(f.TimePlanned),
+			clock(f.TimeCurrent),
+			formatDelay(f.Delay()),
 			f.Airline, f.Airplane)
 	}
-	tw.Flush()
+	return tw.Flush()
+}
+
+// clock formats t as 15:04. Zero t is empty.
+func clock(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("15:04")
+}
+
+// formatDelay formats d like +1h5m or -10m. Zero d is empty.
+func formatDelay(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d == 0 {
+		return ""
+	}
+	s := d.String()
+	s = s[:len(s)-2] // remove 0s
+	if d > 0 {
+		s = "+" + s
+	}
+	return s
 }
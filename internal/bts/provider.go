@@ -0,0 +1,63 @@
+package bts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Provider gets flights of an airport, typically by fetching and parsing
+// airport's website.
+type Provider interface {
+	Arrivals() (Flights, error)
+	Departures() (Flights, error)
+}
+
+// Airports are providers of supported airports keyed by lowercase IATA code.
+var Airports = map[string]Provider{
+	"bts": BTS{},
+}
+
+// AirportCodes returns sorted codes of supported airports.
+func AirportCodes() []string {
+	var codes []string
+	for code := range Airports {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// LookupAirport returns provider of airport with IATA code.
+func LookupAirport(code string) (Provider, error) {
+	p, ok := Airports[strings.ToLower(code)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported airport %q, use one of %s", code, strings.Join(AirportCodes(), ", "))
+	}
+	return p, nil
+}
+
+// GetFlights returns the flights of arrival and/or departure type from p.
+func GetFlights(p Provider, ft FlightType) (Flights, error) {
+	switch ft {
+	case Arrival:
+		return p.Arrivals()
+	case Departure:
+		return p.Departures()
+	case Both:
+		arrivals, err := p.Arrivals()
+		if err != nil {
+			return nil, err
+		}
+		departures, err := p.Departures()
+		if err != nil {
+			return nil, err
+		}
+		var both Flights
+		both = append(both, arrivals...)
+		both = append(both, departures...)
+		return both, nil
+	default:
+		return nil, fmt.Errorf("unknown flight type")
+	}
+}
@@ -0,0 +1,51 @@
+package bts
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeProvider struct {
+	arrivals, departures Flights
+	err                  error
+}
+
+func (p fakeProvider) Arrivals() (Flights, error)   { return p.arrivals, p.err }
+func (p fakeProvider) Departures() (Flights, error) { return p.departures, p.err }
+
+func TestGetFlights(t *testing.T) {
+	p := fakeProvider{
+		arrivals:   Flights{{Type: Arrival, Number: "A1"}},
+		departures: Flights{{Type: Departure, Number: "D1"}, {Type: Departure, Number: "D2"}},
+	}
+	tests := []struct {
+		ft   FlightType
+		want int // flights count
+	}{
+		{Arrival, 1},
+		{Departure, 2},
+		{Both, 3},
+	}
+	for _, tt := range tests {
+		flights, err := GetFlights(p, tt.ft)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(flights) != tt.want {
+			t.Errorf("GetFlights(%v) returned %d flights, want %d", tt.ft, len(flights), tt.want)
+		}
+	}
+
+	if _, err := GetFlights(fakeProvider{err: errors.New("down")}, Both); err == nil {
+		t.Error("GetFlights with failing provider returned nil error")
+	}
+}
+
+func TestLookupAirport(t *testing.T) {
+	if _, err := LookupAirport("BTS"); err != nil {
+		t.Errorf("LookupAirport(BTS) = %v", err)
+	}
+	if _, err := LookupAirport("xyz"); err == nil {
+		t.Error("LookupAirport(xyz) returned nil error")
+	}
+}
@@ -0,0 +1,148 @@
+package bts
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// EventType is a kind of change of flights.
+type EventType int
+
+const (
+	NewFlight EventType = iota
+	TimeChanged
+	DelayExceeded
+	FlightDisappeared
+)
+
+func (et EventType) String() string {
+	return [...]string{"new", "time changed", "delay exceeded", "disappeared"}[et]
+}
+
+// Event is a change of a flight between two snapshots.
+type Event struct {
+	Type     EventType
+	Flight   Flight
+	Previous Flight // zero for new flights
+}
+
+func (e Event) String() string {
+	f := e.Flight
+	s := fmt.Sprintf("%s %s %s %s", f.Type, f.Number, f.Destination, clock(f.TimePlanned))
+	switch e.Type {
+	case TimeChanged, DelayExceeded:
+		return fmt.Sprintf("%s: %s, now %s (%s)", e.Type, s, clock(f.Time()), formatDelay(f.Delay()))
+	default:
+		return fmt.Sprintf("%s: %s", e.Type, s)
+	}
+}
+
+// flightKey identifies a flight in snapshots. Type is needed because a
+// through flight arrives and departs with the same number on the same day.
+type flightKey struct {
+	typ    FlightType
+	number string
+	date   time.Time
+}
+
+func keyOf(f Flight) flightKey {
+	return flightKey{typ: f.Type, number: f.Number, date: f.Date}
+}
+
+// Diff returns events that happened between old and new snapshot of
+// flights. DelayExceeded is returned instead of TimeChanged when the delay
+// grows beyond maxDelay; zero maxDelay disables it.
+func Diff(old, new Flights, maxDelay time.Duration) []Event {
+	oldFlights := make(map[flightKey]Flight)
+	for _, f := range old {
+		oldFlights[keyOf(f)] = f
+	}
+	newFlights := make(map[flightKey]bool)
+
+	var events []Event
+	for _, f := range new {
+		newFlights[keyOf(f)] = true
+		prev, ok := oldFlights[keyOf(f)]
+		switch {
+		case !ok:
+			events = append(events, Event{Type: NewFlight, Flight: f})
+		case maxDelay > 0 && prev.Delay() <= maxDelay && f.Delay() > maxDelay:
+			events = append(events, Event{Type: DelayExceeded, Flight: f, Previous: prev})
+		case !prev.Time().Equal(f.Time()):
+			events = append(events, Event{Type: TimeChanged, Flight: f, Previous: prev})
+		}
+	}
+	for _, f := range old {
+		if !newFlights[keyOf(f)] {
+			events = append(events, Event{Type: FlightDisappeared, Flight: f, Previous: f})
+		}
+	}
+	return events
+}
+
+// Watcher polls flights and notifies about changes.
+type Watcher struct {
+	Provider Provider
+	Type     FlightType
+	Interval time.Duration
+	MaxDelay time.Duration // see Diff
+
+	// Filter selects flights to watch; nil means all.
+	Filter func(Flights) Flights
+
+	// Snapshot, if not nil, is called with all flights got, e.g. to
+	// archive them.
+	Snapshot func(Flights) error
+
+	// Notify is called for each event. Errors getting flights or notifying
+	// are passed to Error, if not nil, and watching continues.
+	Notify func(Event) error
+	Error  func(error)
+}
+
+// Watch gets flights every Interval and notifies about changes since the
+// previous snapshot until ctx is done. The first snapshot produces no events.
+func (w Watcher) Watch(ctx context.Context) error {
+	var snapshot Flights
+	var haveSnapshot bool
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		flights, err := GetFlights(w.Provider, w.Type)
+		if err == nil && w.Snapshot != nil {
+			if err := w.Snapshot(flights); err != nil {
+				w.error(err)
+			}
+		}
+		if err == nil && w.Filter != nil {
+			flights = w.Filter(flights)
+		}
+		switch {
+		case err != nil:
+			w.error(err)
+		case !haveSnapshot:
+			snapshot, haveSnapshot = flights, true
+		default:
+			for _, e := range Diff(snapshot, flights, w.MaxDelay) {
+				if err := w.Notify(e); err != nil {
+					w.error(fmt.Errorf("notifying about %s: %v", e, err))
+				}
+			}
+			snapshot = flights
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w Watcher) error(err error) {
+	if w.Error != nil {
+		w.Error(err)
+	}
+}
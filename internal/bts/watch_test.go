@@ -0,0 +1,127 @@
+package bts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// page returns HTML page with a flight button per flight given as
+// "number planned current".
+func page(flights ...string) string {
+	var b strings.Builder
+	b.WriteString("<html><body>")
+	for _, f := range flights {
+		var number, planned, current string
+		fmt.Sscan(f, &number, &planned, &current)
+		fmt.Fprintf(&b, `<button data-flight-type="arrival" data-destination="Paris" data-flight-number="%s" `+
+			`data-flight-date="12. 06. 2023" data-flight-time-planed="%s" data-flight-time-current="%s"></button>`,
+			number, planned, current)
+	}
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+func flight(number, planned, current string) Flight {
+	f := Flight{Number: number, Date: parseDate("2023-06-12"), TimePlanned: parseTime("2023-06-12 " + planned)}
+	if current != "" {
+		f.TimeCurrent = parseTime("2023-06-12 " + current)
+	}
+	return f
+}
+
+func TestDiff(t *testing.T) {
+	old := Flights{
+		flight("A1", "08:00", ""),
+		flight("A2", "09:00", ""),
+		flight("A3", "10:00", "10:10"),
+		flight("A4", "11:00", ""),
+	}
+	new := Flights{
+		flight("A1", "08:00", ""),      // unchanged
+		flight("A2", "09:00", "09:10"), // delayed a bit
+		flight("A3", "10:00", "10:40"), // delayed a lot
+		flight("A5", "12:00", ""),      // new
+	}
+	var got []string
+	for _, e := range Diff(old, new, 30*time.Minute) {
+		got = append(got, e.Type.String()+" "+e.Flight.Number)
+	}
+	want := []string{"time changed A2", "delay exceeded A3", "new A5", "disappeared A4"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+}
+
+func TestDiffThroughFlight(t *testing.T) {
+	arrival, departure := flight("T1", "09:00", ""), flight("T1", "10:00", "")
+	arrival.Type, departure.Type = Arrival, Departure
+	if events := Diff(Flights{arrival, departure}, Flights{arrival, departure}, 30*time.Minute); len(events) != 0 {
+		t.Errorf("Diff() = %v, want no events", events)
+	}
+}
+
+func TestWatcherWatch(t *testing.T) {
+	pages := []string{
+		page("A1 08:00", "A2 09:00"),
+		page("A1 08:00 08:45", "A3 10:00"),
+	}
+	var mu sync.Mutex
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprint(w, pages[min(requests, len(pages)-1)])
+		requests++
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var events []string
+	w := Watcher{
+		Provider: BTS{ArrivalsURL: srv.URL},
+		Type:     Arrival,
+		Interval: 10 * time.Millisecond,
+		MaxDelay: 15 * time.Minute,
+		Notify: func(e Event) error {
+			events = append(events, e.Type.String()+" "+e.Flight.Number)
+			if len(events) == 3 {
+				cancel()
+			}
+			return nil
+		},
+		Error: func(err error) { t.Error(err) },
+	}
+	if err := w.Watch(ctx); err != context.Canceled {
+		t.Fatalf("Watch() = %v, want %v", err, context.Canceled)
+	}
+	want := []string{"delay exceeded A1", "new A3", "disappeared A2"}
+	if strings.Join(events, ", ") != strings.Join(want, ", ") {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got webhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	e := Event{Type: DelayExceeded, Flight: flight("A1", "08:00", "08:45")}
+	if err := WebhookNotifier(srv.URL)(e); err != nil {
+		t.Fatal(err)
+	}
+	if got.Event != "delay exceeded" || got.Number != "A1" || got.DelayMinutes != 45 || got.Current == nil {
+		t.Errorf("webhook got %+v", got)
+	}
+}
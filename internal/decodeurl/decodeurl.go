@@ -1,76 +1,335 @@
 package decodeurl
 
 import (
+	"bufio"
 	"fmt"
 	"log"
 	"net/url"
 	"os"
-	"sort"
 	"strings"
+	"text/tabwriter"
 )
 
 type DecodedURL struct {
-	Scheme string
-	Host   string
-	Path   string
+	Scheme      string
+	User        string
+	Password    string // masked when printed
+	HasPassword bool
+	Host        string // hostname and port as in the URL
+	Hostname    string
+	Port        string
+	DefaultPort bool   // Port is not in the URL but implied by Scheme
+	Opaque      string // data of URLs like mailto:user@example.com
+	Path        string
+	RawPath     string    // escaped form of Path as in the URL
+	Segments    []Segment // of RawPath
+	RawQuery    string
+	Query       []QueryParam // in original order including repeated keys
 	QueryKeyValuePairs
+	Fragment    string
+	RawFragment string // escaped form of Fragment as in the URL
 }
 
-type QueryKeyValuePairs map[string][]string
+// Segment is a part of path between slashes.
+type Segment struct {
+	Raw     string
+	Decoded string
+}
 
-func (du DecodedURL) String() string {
-	return fmt.Sprintf(`
-Scheme	%s 
-Host	%s
-Path	%s
-Query
-%s`,
-		du.Scheme, du.Host, du.Path, parseQuery(du.QueryKeyValuePairs))
+// QueryParam is a key and value from query string.
+type QueryParam struct {
+	Key    string
+	Value  string
+	Nested *Nested // Value decoded if it's an URL or encoded
 }
 
-func parseQuery(q QueryKeyValuePairs) string {
-	var output []string
+type QueryKeyValuePairs map[string][]string
 
-	// Sort map by keys.
-	keys := make([]string, 0, len(q))
-	for k := range q {
-		keys = append(keys, k)
+// defaultPorts are ports implied by schemes.
+var defaultPorts = map[string]string{
+	"ftp":   "21",
+	"ssh":   "22",
+	"http":  "80",
+	"ws":    "80",
+	"https": "443",
+	"wss":   "443",
+}
+
+func (du DecodedURL) String() string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s\t%s\n", name, value)
+		}
 	}
-	sort.Strings(keys)
 
-	for _, k := range keys {
-		output = append(output, fmt.Sprintf("\t%s = %s", k, q[k]))
+	field("Scheme", du.Scheme)
+	field("User", du.User)
+	if du.HasPassword {
+		field("Password", strings.Repeat("*", 8))
+	}
+	field("Host", du.Host)
+	if du.Hostname != du.Host {
+		field("Hostname", du.Hostname)
+	}
+	if du.DefaultPort {
+		field("Port", du.Port+" (default)")
+	} else {
+		field("Port", du.Port)
+	}
+	field("Opaque", du.Opaque)
+	field("Path", du.Path)
+	if du.RawPath != du.Path {
+		field("RawPath", du.RawPath)
+	}
+	if len(du.Segments) > 1 || len(du.Segments) == 1 && du.Segments[0].Raw != du.Segments[0].Decoded {
+		fmt.Fprintln(tw, "Segments")
+		for _, s := range du.Segments {
+			if s.Raw == s.Decoded {
+				fmt.Fprintf(tw, "  %s\n", s.Decoded)
+			} else {
+				fmt.Fprintf(tw, "  %s = %s\n", s.Raw, s.Decoded)
+			}
+		}
+	}
+	if len(du.Query) > 0 {
+		fmt.Fprintln(tw, "Query")
+		for _, p := range du.Query {
+			fmt.Fprintf(tw, "  %s = %s\n", p.Key, p.Value)
+			if p.Nested != nil {
+				for _, line := range p.Nested.lines() {
+					fmt.Fprintf(tw, "    %s\n", line)
+				}
+			}
+		}
+	}
+	field("Fragment", du.Fragment)
+	if du.RawFragment != du.Fragment {
+		field("RawFragment", du.RawFragment)
 	}
-	return strings.Join(output, "\n")
+	tw.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
 }
 
+// Decode decodes URL. Query parameter values that are URLs, percent-encoded
+// (again), base64 encoded or JWTs are decoded recursively.
 func Decode(URL string) (DecodedURL, error) {
+	return decode(URL, 0)
+}
+
+func decode(URL string, depth int) (DecodedURL, error) {
 	u, err := url.Parse(URL)
 	if err != nil {
 		return DecodedURL{}, err
 	}
 	du := DecodedURL{
-		Scheme:             u.Scheme,
-		Host:               u.Host,
-		Path:               u.Path,
-		QueryKeyValuePairs: QueryKeyValuePairs(u.Query()),
+		Scheme:      u.Scheme,
+		Host:        u.Host,
+		Hostname:    u.Hostname(),
+		Port:        u.Port(),
+		Opaque:      u.Opaque,
+		Path:        u.Path,
+		RawPath:     u.EscapedPath(),
+		RawQuery:    u.RawQuery,
+		Query:       orderedQuery(u.RawQuery, depth),
+		Fragment:    u.Fragment,
+		RawFragment: u.EscapedFragment(),
+	}
+	du.QueryKeyValuePairs = queryKeyValuePairs(du.Query)
+	if u.User != nil {
+		du.User = u.User.Username()
+		du.Password, du.HasPassword = u.User.Password()
 	}
+	du.setDefaultPort()
+	du.Segments = segments(du.RawPath)
 	return du, nil
 }
 
+// setDefaultPort sets Port implied by Scheme if it's not in the URL.
+func (du *DecodedURL) setDefaultPort() {
+	if du.Port == "" && du.Host != "" {
+		du.Port = defaultPorts[du.Scheme]
+		du.DefaultPort = du.Port != ""
+	}
+}
+
+// segments splits escaped path to segments.
+func segments(rawPath string) []Segment {
+	if rawPath == "" {
+		return nil
+	}
+	var segs []Segment
+	for _, raw := range strings.Split(strings.TrimPrefix(rawPath, "/"), "/") {
+		decoded, err := url.PathUnescape(raw)
+		if err != nil {
+			decoded = raw
+		}
+		segs = append(segs, Segment{Raw: raw, Decoded: decoded})
+	}
+	return segs
+}
+
+// orderedQuery parses query keeping the order of parameters and repeated
+// keys. Keys and values that can't be unescaped are kept as they are.
+func orderedQuery(query string, depth int) []QueryParam {
+	var params []QueryParam
+	for _, part := range strings.Split(query, "&") {
+		if part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		value = queryUnescape(value)
+		params = append(params, QueryParam{Key: queryUnescape(key), Value: value, Nested: decodeValue(value, depth)})
+	}
+	return params
+}
+
+// queryKeyValuePairs groups values of params by key.
+func queryKeyValuePairs(params []QueryParam) QueryKeyValuePairs {
+	pairs := QueryKeyValuePairs{}
+	for _, p := range params {
+		pairs[p.Key] = append(pairs[p.Key], p.Value)
+	}
+	return pairs
+}
+
+func queryUnescape(s string) string {
+	unescaped, err := url.QueryUnescape(s)
+	if err != nil {
+		return s
+	}
+	return unescaped
+}
+
 const usage = `Usage: decodeurl <url>
+       decodeurl <command> <url> [args]
+
+Decode URL to human readable form or modify URL and print it re-encoded to
+canonical form. URL - means URLs read from stdin, one per line; decoded
+URLs are then separated by an empty line.
+
+Commands:
+  encode              only re-encode
+  set key=value...    set query parameters replacing existing values
+  add key=value...    add query parameters
+  del key...          delete query parameters
+  strip               delete tracking query parameters (utm_*, fbclid, gclid)
+  scheme scheme       change scheme
+  host host[:port]    change host
+  path path           change path
 
-Example: decodeurl 'https://example.com/some%21/path?key1=value1,value2&key2=abc%2B%2B'
+Examples:
+  decodeurl 'https://example.com/some%21/path?key1=value1,value2&key2=abc%2B%2B'
+  decodeurl set 'https://example.com/?page=1' page=2 lang=en
+  cat urls.txt | decodeurl strip - | sort -u
+  pbpaste | decodeurl -
 `
 
 func Main() {
-	if len(os.Args) != 2 {
+	log.SetFlags(0)
+	log.SetPrefix("decodeurl: ")
+
+	if len(os.Args) == 2 {
+		for i, URL := range readURLs(os.Args[1]) {
+			du, err := Decode(URL)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Println(du)
+		}
+		return
+	}
+	if len(os.Args) < 3 {
 		fmt.Print(usage)
 		os.Exit(1)
 	}
-	du, err := Decode(os.Args[1])
+	modify, err := command(os.Args[1], os.Args[3:])
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println(du)
+	for _, URL := range readURLs(os.Args[2]) {
+		du, err := Decode(URL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := modify(&du); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(du.Encode())
+	}
+}
+
+// readURLs returns arg or, if arg is -, non-empty lines of stdin.
+func readURLs(arg string) []string {
+	if arg != "-" {
+		return []string{arg}
+	}
+	var URLs []string
+	s := bufio.NewScanner(os.Stdin)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			URLs = append(URLs, line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		log.Fatal(err)
+	}
+	return URLs
+}
+
+// command returns function modifying URL as command with args says.
+func command(name string, args []string) (func(*DecodedURL) error, error) {
+	nargs := func(n int) error {
+		if n >= 0 && len(args) != n || n < 0 && len(args) == 0 {
+			return fmt.Errorf("%s: wrong number of arguments", name)
+		}
+		return nil
+	}
+	keyValues := func(modify func(du *DecodedURL, key, value string)) (func(*DecodedURL) error, error) {
+		if err := nargs(-1); err != nil {
+			return nil, err
+		}
+		for _, arg := range args {
+			if !strings.Contains(arg, "=") {
+				return nil, fmt.Errorf("%s: %q is not key=value", name, arg)
+			}
+		}
+		return func(du *DecodedURL) error {
+			for _, arg := range args {
+				key, value, _ := strings.Cut(arg, "=")
+				modify(du, key, value)
+			}
+			return nil
+		}, nil
+	}
+
+	switch name {
+	case "encode":
+		return func(*DecodedURL) error { return nil }, nargs(0)
+	case "set":
+		return keyValues((*DecodedURL).SetQuery)
+	case "add":
+		return keyValues((*DecodedURL).AddQuery)
+	case "del":
+		return func(du *DecodedURL) error {
+			for _, key := range args {
+				du.DelQuery(key)
+			}
+			return nil
+		}, nargs(-1)
+	case "strip":
+		return func(du *DecodedURL) error { du.StripTracking(); return nil }, nargs(0)
+	case "scheme":
+		return func(du *DecodedURL) error { du.SetScheme(args[0]); return nil }, nargs(1)
+	case "host":
+		return func(du *DecodedURL) error { return du.SetHost(args[0]) }, nargs(1)
+	case "path":
+		return func(du *DecodedURL) error { du.SetPath(args[0]); return nil }, nargs(1)
+	default:
+		return nil, fmt.Errorf("unknown command %q", name)
+	}
 }
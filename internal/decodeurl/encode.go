@@ -0,0 +1,155 @@
+package decodeurl
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// TrackingParams are query parameters used to track visitors. Keys ending
+// with * are prefixes.
+var TrackingParams = []string{"utm_*", "fbclid", "gclid"}
+
+// SetScheme changes scheme. Default port follows the scheme.
+func (du *DecodedURL) SetScheme(scheme string) {
+	du.Scheme = scheme
+	if du.DefaultPort {
+		du.Port, du.DefaultPort = "", false
+	}
+	du.setDefaultPort()
+}
+
+// SetHost changes host given as hostname and optional port.
+func (du *DecodedURL) SetHost(host string) error {
+	u, err := url.Parse("//" + host)
+	if err != nil {
+		return err
+	}
+	if u.Host != host {
+		return fmt.Errorf("invalid host %q", host)
+	}
+	du.Host, du.Hostname, du.Port, du.DefaultPort = u.Host, u.Hostname(), u.Port(), false
+	du.setDefaultPort()
+	return nil
+}
+
+// SetPath changes path given unescaped.
+func (du *DecodedURL) SetPath(path string) {
+	du.Path = path
+	du.RawPath = (&url.URL{Path: path}).EscapedPath()
+	du.Segments = segments(du.RawPath)
+}
+
+// SetQuery sets query parameter key to value. It replaces the first
+// parameter with key and deletes the others or appends a new parameter.
+func (du *DecodedURL) SetQuery(key, value string) {
+	var params []QueryParam
+	var set bool
+	for _, p := range du.Query {
+		if p.Key != key {
+			params = append(params, p)
+		} else if !set {
+			params = append(params, newQueryParam(key, value))
+			set = true
+		}
+	}
+	if !set {
+		params = append(params, newQueryParam(key, value))
+	}
+	du.setQuery(params)
+}
+
+// AddQuery appends query parameter key with value keeping existing
+// parameters with the same key.
+func (du *DecodedURL) AddQuery(key, value string) {
+	du.setQuery(append(du.Query, newQueryParam(key, value)))
+}
+
+// DelQuery deletes all query parameters with key.
+func (du *DecodedURL) DelQuery(key string) {
+	du.deleteQuery(func(k string) bool { return k == key })
+}
+
+// StripTracking deletes query parameters matching TrackingParams.
+func (du *DecodedURL) StripTracking() {
+	du.deleteQuery(isTracking)
+}
+
+func isTracking(key string) bool {
+	for _, t := range TrackingParams {
+		if prefix, ok := strings.CutSuffix(t, "*"); ok && strings.HasPrefix(key, prefix) || key == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (du *DecodedURL) deleteQuery(match func(key string) bool) {
+	var params []QueryParam
+	for _, p := range du.Query {
+		if !match(p.Key) {
+			params = append(params, p)
+		}
+	}
+	du.setQuery(params)
+}
+
+func newQueryParam(key, value string) QueryParam {
+	return QueryParam{Key: key, Value: value, Nested: decodeValue(value, 0)}
+}
+
+// setQuery sets Query to params and updates RawQuery and QueryKeyValuePairs.
+func (du *DecodedURL) setQuery(params []QueryParam) {
+	du.Query = params
+	du.RawQuery = encodeQuery(params)
+	du.QueryKeyValuePairs = queryKeyValuePairs(params)
+}
+
+// encodeQuery escapes params in their order.
+func encodeQuery(params []QueryParam) string {
+	var parts []string
+	for _, p := range params {
+		parts = append(parts, url.QueryEscape(p.Key)+"="+url.QueryEscape(p.Value))
+	}
+	return strings.Join(parts, "&")
+}
+
+// Encode returns canonical form of the URL: scheme and hostname are
+// lowercased, default port is left out, empty path is / and query
+// parameters are escaped consistently in their order.
+func (du DecodedURL) Encode() string {
+	u := url.URL{
+		Scheme:      strings.ToLower(du.Scheme),
+		Opaque:      du.Opaque,
+		Path:        du.Path,
+		RawPath:     du.RawPath,
+		RawQuery:    encodeQuery(du.Query),
+		Fragment:    du.Fragment,
+		RawFragment: du.RawFragment,
+	}
+	if du.HasPassword {
+		u.User = url.UserPassword(du.User, du.Password)
+	} else if du.User != "" {
+		u.User = url.User(du.User)
+	}
+	if du.Host != "" {
+		hostname := strings.ToLower(du.Hostname)
+		port := du.Port
+		if du.DefaultPort || port == defaultPorts[u.Scheme] {
+			port = ""
+		}
+		switch {
+		case port != "":
+			u.Host = net.JoinHostPort(hostname, port)
+		case strings.Contains(hostname, ":"):
+			u.Host = "[" + hostname + "]"
+		default:
+			u.Host = hostname
+		}
+		if u.Path == "" {
+			u.Path, u.RawPath = "/", ""
+		}
+	}
+	return u.String()
+}
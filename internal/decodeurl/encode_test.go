@@ -0,0 +1,116 @@
+package decodeurl_test
+
+import (
+	"testing"
+
+	"github.com/jreisinger/tools/internal/decodeurl"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodedURL_Encode(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		URL  string
+		want string
+	}{
+		{"https://example.com", "https://example.com/"},
+		{"http://b.com?x=2", "http://b.com/?x=2"},
+		{"http://b.com#top", "http://b.com/#top"},
+		{"HTTPS://Example.COM:443/a%2Fb?b=x+y&a=%zz#sec%20tion", "https://example.com/a%2Fb?b=x+y&a=%25zz#sec%20tion"},
+		{"http://user:secret@[::1]:8080/?q", "http://user:secret@[::1]:8080/?q="},
+		{"http://[::1]:80/", "http://[::1]/"},
+		{"mailto:user@example.com", "mailto:user@example.com"},
+	}
+	for _, tt := range tests {
+		du, err := decodeurl.Decode(tt.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := du.Encode(); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.URL, got, tt.want)
+		}
+	}
+}
+
+func TestDecodedURL_Modify(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		modify func(*decodeurl.DecodedURL) error
+		want   string
+	}{
+		{
+			name:   "set replaces first and deletes others",
+			modify: func(du *decodeurl.DecodedURL) error { du.SetQuery("a", "x&y"); return nil },
+			want:   "http://example.com/p?a=x%26y&b=2&utm_source=news&fbclid=abc",
+		},
+		{
+			name:   "set appends new",
+			modify: func(du *decodeurl.DecodedURL) error { du.SetQuery("c", "3"); return nil },
+			want:   "http://example.com/p?a=1&b=2&a=3&utm_source=news&fbclid=abc&c=3",
+		},
+		{
+			name:   "add",
+			modify: func(du *decodeurl.DecodedURL) error { du.AddQuery("a", "4"); return nil },
+			want:   "http://example.com/p?a=1&b=2&a=3&utm_source=news&fbclid=abc&a=4",
+		},
+		{
+			name:   "del",
+			modify: func(du *decodeurl.DecodedURL) error { du.DelQuery("a"); return nil },
+			want:   "http://example.com/p?b=2&utm_source=news&fbclid=abc",
+		},
+		{
+			name:   "strip tracking",
+			modify: func(du *decodeurl.DecodedURL) error { du.StripTracking(); return nil },
+			want:   "http://example.com/p?a=1&b=2&a=3",
+		},
+		{
+			name:   "scheme changes default port",
+			modify: func(du *decodeurl.DecodedURL) error { du.SetScheme("https"); return nil },
+			want:   "https://example.com/p?a=1&b=2&a=3&utm_source=news&fbclid=abc",
+		},
+		{
+			name:   "host",
+			modify: func(du *decodeurl.DecodedURL) error { return du.SetHost("example.org:8443") },
+			want:   "http://example.org:8443/p?a=1&b=2&a=3&utm_source=news&fbclid=abc",
+		},
+		{
+			name:   "path",
+			modify: func(du *decodeurl.DecodedURL) error { du.SetPath("/a b/c"); return nil },
+			want:   "http://example.com/a%20b/c?a=1&b=2&a=3&utm_source=news&fbclid=abc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			du, err := decodeurl.Decode("http://example.com/p?a=1&b=2&a=3&utm_source=news&fbclid=abc")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.modify(&du); err != nil {
+				t.Fatal(err)
+			}
+			if got := du.Encode(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			// Decoded fields are kept consistent with the modified URL.
+			want, err := decodeurl.Decode(tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(want, du) {
+				t.Error(cmp.Diff(want, du))
+			}
+		})
+	}
+}
+
+func TestDecodedURL_SetHostRejectsInvalidHost(t *testing.T) {
+	t.Parallel()
+	var du decodeurl.DecodedURL
+	for _, host := range []string{"example.com/path", "a b", "[::1"} {
+		if err := du.SetHost(host); err == nil {
+			t.Errorf("%q: want error", host)
+		}
+	}
+}
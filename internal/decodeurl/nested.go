@@ -0,0 +1,162 @@
+package decodeurl
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxDepth limits how deep values are recursively decoded.
+const maxDepth = 10
+
+// Nested is a value found inside a query parameter value, like an URL or
+// base64 encoded text. It's decoded recursively, so for example a
+// percent-encoded URL is a Nested with Encoding percent whose Next is a
+// Nested with Encoding url.
+type Nested struct {
+	Encoding string      // url, percent, base64, base64url or jwt
+	Value    string      // decoded value; JWT's header and payload joined by a dot
+	URL      *DecodedURL // for url encoding
+	Header   string      // JWT's header JSON
+	Payload  string      // JWT's payload JSON
+	Next     *Nested     // Value decoded further
+}
+
+// decodeValue returns s decoded if it's URL or encoded, otherwise nil.
+func decodeValue(s string, depth int) *Nested {
+	if depth >= maxDepth {
+		return nil
+	}
+	if isURL(s) {
+		du, err := decode(s, depth+1)
+		if err != nil {
+			return nil
+		}
+		return &Nested{Encoding: "url", Value: s, URL: &du}
+	}
+	if strings.Contains(s, "%") {
+		if unescaped, err := url.QueryUnescape(s); err == nil && unescaped != s {
+			return &Nested{Encoding: "percent", Value: unescaped, Next: decodeValue(unescaped, depth+1)}
+		}
+	}
+	if n := decodeJWT(s); n != nil {
+		return n
+	}
+	if decoded, encoding, ok := decodeBase64(s); ok {
+		return &Nested{Encoding: encoding, Value: decoded, Next: decodeValue(decoded, depth+1)}
+	}
+	return nil
+}
+
+// isURL reports whether s is an absolute URL with a host.
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != "" && !strings.ContainsAny(s, " \n")
+}
+
+var (
+	base64Chars    = regexp.MustCompile(`^[A-Za-z0-9+/]+={0,2}$`)
+	base64URLChars = regexp.MustCompile(`^[A-Za-z0-9_-]+={0,2}$`)
+)
+
+// minBase64Len is minimal length of a value considered base64 encoded, so
+// short words are not decoded.
+const minBase64Len = 8
+
+// decodeBase64 decodes s encoded as standard or URL base64, padded or not.
+// Only printable text is considered decoded.
+func decodeBase64(s string) (decoded, encoding string, ok bool) {
+	if len(s) < minBase64Len {
+		return "", "", false
+	}
+	var encodings []*base64.Encoding
+	switch {
+	case base64Chars.MatchString(s):
+		encoding = "base64"
+		encodings = []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding}
+	case base64URLChars.MatchString(s):
+		encoding = "base64url"
+		encodings = []*base64.Encoding{base64.URLEncoding, base64.RawURLEncoding}
+	default:
+		return "", "", false
+	}
+	for _, enc := range encodings {
+		data, err := enc.DecodeString(s)
+		if err == nil && isText(data) {
+			return string(data), encoding, true
+		}
+	}
+	return "", "", false
+}
+
+// isText reports whether data is printable UTF-8 text.
+func isText(data []byte) bool {
+	if len(data) == 0 || !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeJWT decodes header and payload of JSON Web Token s. The signature
+// is not verified.
+func decodeJWT(s string) *Nested {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	var jsons []string
+	for _, part := range parts[:2] {
+		data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+		if err != nil {
+			return nil
+		}
+		var obj map[string]any
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return nil
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, data); err != nil {
+			return nil
+		}
+		jsons = append(jsons, compact.String())
+	}
+	return &Nested{
+		Encoding: "jwt",
+		Value:    jsons[0] + "." + jsons[1],
+		Header:   jsons[0],
+		Payload:  jsons[1],
+	}
+}
+
+// lines returns n formatted as lines indented by two spaces per level.
+func (n *Nested) lines() []string {
+	var lines []string
+	switch n.Encoding {
+	case "url":
+		lines = append(lines, "url")
+		for _, line := range strings.Split(n.URL.String(), "\n") {
+			lines = append(lines, "  "+line)
+		}
+	case "jwt":
+		lines = append(lines, "jwt", "  header   "+n.Header, "  payload  "+n.Payload)
+	default:
+		lines = append(lines, fmt.Sprintf("%s: %s", n.Encoding, n.Value))
+	}
+	if n.Next != nil {
+		for _, line := range n.Next.lines() {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,242 @@
+package ghstats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+	"golang.org/x/oauth2"
+)
+
+// Auth is how the client authenticates. Either Token or AppID with
+// PrivateKey must be set.
+type Auth struct {
+	Token string // personal access token
+
+	// GitHub App authentication. Installation tokens are created and
+	// refreshed as needed. If InstallationID is zero, the app's first
+	// installation is used.
+	AppID          int64
+	InstallationID int64
+	PrivateKey     []byte // PEM encoded
+}
+
+// NewClient returns GitHub API client authenticated via auth. Empty baseURL
+// means github.com, otherwise it's URL of GitHub Enterprise Server, like
+// https://github.example.com.
+func NewClient(ctx context.Context, baseURL string, auth Auth) (*github.Client, error) {
+	newClient := func(hc *http.Client) (*github.Client, error) {
+		if baseURL == "" {
+			return github.NewClient(hc), nil
+		}
+		return github.NewEnterpriseClient(baseURL, baseURL, hc)
+	}
+
+	var ts oauth2.TokenSource
+	switch {
+	case auth.AppID != 0:
+		key, err := parsePrivateKey(auth.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		jwt := &appJWTSource{appID: auth.AppID, key: key}
+		appClient, err := newClient(oauth2.NewClient(ctx, jwt))
+		if err != nil {
+			return nil, err
+		}
+		ts = oauth2.ReuseTokenSource(nil, &installationTokenSource{
+			ctx:            ctx,
+			client:         appClient,
+			installationID: auth.InstallationID,
+		})
+	case auth.Token != "":
+		ts = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: auth.Token})
+	default:
+		return nil, errors.New("no token or GitHub App credentials")
+	}
+	return newClient(oauth2.NewClient(ctx, ts))
+}
+
+// appJWTSource creates JSON Web Tokens used to authenticate as a GitHub App.
+type appJWTSource struct {
+	appID int64
+	key   *rsa.PrivateKey
+}
+
+// Token returns JWT valid for 10 minutes, the maximum GitHub allows.
+func (s *appJWTSource) Token() (*oauth2.Token, error) {
+	now := time.Now()
+	expiry := now.Add(9 * time.Minute)
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	claims := map[string]int64{
+		"iat": now.Add(-time.Minute).Unix(), // allow for clock drift
+		"exp": expiry.Unix(),
+		"iss": s.appID,
+	}
+
+	var parts []string
+	for _, v := range []any{header, claims} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, base64.RawURLEncoding.EncodeToString(data))
+	}
+	signingInput := strings.Join(parts, ".")
+	hash := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	jwt := signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+	return &oauth2.Token{AccessToken: jwt, TokenType: "Bearer", Expiry: expiry}, nil
+}
+
+// installationTokenSource creates GitHub App installation tokens.
+type installationTokenSource struct {
+	ctx            context.Context
+	client         *github.Client // authenticated as the app
+	installationID int64
+}
+
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	if s.installationID == 0 {
+		installations, _, err := s.client.Apps.ListInstallations(s.ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing app installations: %v", err)
+		}
+		if len(installations) == 0 {
+			return nil, errors.New("app is not installed")
+		}
+		s.installationID = installations[0].GetID()
+	}
+	token, _, err := s.client.Apps.CreateInstallationToken(s.ctx, s.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating installation token: %v", err)
+	}
+	return &oauth2.Token{AccessToken: token.GetToken(), Expiry: token.GetExpiresAt().Time}, nil
+}
+
+// parsePrivateKey parses PEM encoded RSA private key in PKCS #1 (as
+// downloaded from GitHub) or PKCS #8 form.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM encoded private key found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %v", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not RSA")
+	}
+	return rsaKey, nil
+}
+
+// TokenFromFile reads token from file.
+func TokenFromFile(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("no token in %s", file)
+	}
+	return token, nil
+}
+
+// TokenFromGh returns token the gh CLI uses for host, like github.com. It's
+// read from gh's hosts.yml or, when gh stores it in a keyring, via gh auth
+// token.
+func TokenFromGh(host string) (string, error) {
+	if token, err := tokenFromGhConfig(filepath.Join(ghConfigDir(), "hosts.yml"), host); err == nil {
+		return token, nil
+	}
+	out, err := exec.Command("gh", "auth", "token", "--hostname", host).Output()
+	if err != nil {
+		return "", fmt.Errorf("getting gh token for %s: %v", host, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ghConfigDir returns gh CLI's configuration directory.
+func ghConfigDir() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "gh")
+}
+
+// tokenFromGhConfig returns oauth_token of host from gh's hosts.yml which
+// looks like:
+//
+//	github.com:
+//	    users:
+//	        jreisinger:
+//	            oauth_token: gho_xxx
+//	    user: jreisinger
+//	    oauth_token: gho_xxx
+//
+// Since 2.40 gh keeps tokens of all logged in accounts under users: so only
+// oauth_token directly under host, the active account's one, is used.
+func tokenFromGhConfig(file, host string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	var inHost bool
+	var childIndent string // of keys directly under host
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := s.Text()
+		if line == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if indent == "" {
+			inHost = strings.TrimSuffix(strings.TrimSpace(line), ":") == host
+			childIndent = ""
+			continue
+		}
+		if childIndent == "" {
+			childIndent = indent
+		}
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if inHost && indent == childIndent && ok && key == "oauth_token" {
+			if token := strings.Trim(strings.TrimSpace(value), `"'`); token != "" {
+				return token, nil
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no token for %s in %s", host, file)
+}
@@ -0,0 +1,141 @@
+package ghstats
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// verifyJWT checks RS256 signature of jwt and returns its issuer.
+func verifyJWT(t *testing.T, jwt string, key *rsa.PublicKey) int64 {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("want 3 JWT parts, got %d", len(parts))
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig); err != nil {
+		t.Fatalf("verifying JWT: %v", err)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims struct{ Iss, Iat, Exp int64 }
+	if err := json.Unmarshal(data, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Exp-claims.Iat > 600 {
+		t.Errorf("JWT valid for more than 10 minutes")
+	}
+	return claims.Iss
+}
+
+func TestNewClientAuthenticatesAsGitHubApp(t *testing.T) {
+	t.Parallel()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var tokens atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/app/installations", func(w http.ResponseWriter, r *http.Request) {
+		if iss := verifyJWT(t, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), &key.PublicKey); iss != 42 {
+			t.Errorf("want JWT issuer 42, got %d", iss)
+		}
+		writeJSON(w, []map[string]any{{"id": 7}})
+	})
+	mux.HandleFunc("POST /api/v3/app/installations/7/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		verifyJWT(t, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), &key.PublicKey)
+		tokens.Add(1)
+		writeJSON(w, map[string]any{"token": "installation-token", "expires_at": time.Now().Add(time.Hour).Format(time.RFC3339)})
+	})
+	mux.HandleFunc("GET /api/v3/users/{user}", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer installation-token" {
+			t.Errorf("want installation token, got %q", got)
+		}
+		writeJSON(w, map[string]any{"login": r.PathValue("user")})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	ctx := context.Background()
+	client, err := NewClient(ctx, srv.URL, Auth{AppID: 42, PrivateKey: pemKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, _, err := client.Users.Get(ctx, "user"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := tokens.Load(); n != 1 {
+		t.Errorf("want installation token created once, got %d", n)
+	}
+}
+
+func TestNewClientNeedsCredentials(t *testing.T) {
+	t.Parallel()
+	if _, err := NewClient(context.Background(), "", Auth{}); err == nil {
+		t.Error("want error, got nil")
+	}
+}
+
+func TestTokenFromGhConfig(t *testing.T) {
+	t.Parallel()
+	file := filepath.Join(t.TempDir(), "hosts.yml")
+	hosts := `github.com:
+    user: someone
+    oauth_token: gho_public
+    git_protocol: https
+github.example.com:
+    oauth_token: "gho_enterprise"
+github.nested.com:
+    users:
+        someone:
+            oauth_token: gho_inactive
+    user: other
+`
+	if err := os.WriteFile(file, []byte(hosts), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		host    string
+		want    string
+		wantErr bool
+	}{
+		{host: "github.com", want: "gho_public"},
+		{host: "github.example.com", want: "gho_enterprise"},
+		{host: "github.other.com", wantErr: true},
+		{host: "github.nested.com", wantErr: true}, // active account's token is in keyring
+	}
+	for _, test := range tests {
+		got, err := tokenFromGhConfig(file, test.host)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: wantErr %v, got %v", test.host, test.wantErr, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: want %q, got %q", test.host, test.want, got)
+		}
+	}
+}
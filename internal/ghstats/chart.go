@@ -0,0 +1,247 @@
+package ghstats
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// sparklineDays is the maximum number of days shown by a sparkline.
+const sparklineDays = 28
+
+// days returns daily traffic of s from History if available.
+func (s Stat) days() []Day {
+	if len(s.History) > 0 {
+		return s.History
+	}
+	return s.Traffic
+}
+
+// daily returns values of days, one per calendar day from the first to the
+// last one. Missing days are zero. If n > 0 only the last n days are returned.
+func daily(days []Day, n int, value func(Day) int) []int {
+	if len(days) == 0 {
+		return nil
+	}
+	first, last := days[0].Date, days[len(days)-1].Date
+	if n > 0 && last.Sub(first) >= time.Duration(n)*24*time.Hour {
+		first = last.Add(-time.Duration(n-1) * 24 * time.Hour)
+	}
+	values := make([]int, int(last.Sub(first).Hours()/24)+1)
+	for _, d := range days {
+		if i := int(d.Date.Sub(first).Hours() / 24); i >= 0 && i < len(values) {
+			values[i] = value(d)
+		}
+	}
+	return values
+}
+
+// sparkline returns values as a line of block characters. It's empty if
+// there are no values.
+func sparkline(values []int) string {
+	const ticks = "▁▂▃▄▅▆▇█"
+	blocks := []rune(ticks)
+	var highest int
+	for _, v := range values {
+		highest = max(highest, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if highest > 0 {
+			i = v * (len(blocks) - 1) / highest
+		}
+		b.WriteRune(blocks[i])
+	}
+	return b.String()
+}
+
+// visitorsDaily returns daily unique visitors of s shown by sparkline.
+func visitorsDaily(s Stat) []int {
+	return daily(s.days(), sparklineDays, uniqueVisitors)
+}
+
+func uniqueVisitors(d Day) int { return d.UniqueVisitors }
+func uniqueCloners(d Day) int  { return d.UniqueCloners }
+
+// point is a value of a chart at a date.
+type point struct {
+	Date  time.Time
+	Value int
+}
+
+// chart is an SVG line chart.
+type chart struct {
+	Title       string
+	Width       int
+	Height      int
+	Points      string // SVG polyline points
+	Max         int
+	First, Last string // dates
+	NoData      bool
+}
+
+// Chart dimensions in pixels.
+const (
+	chartWidth  = 320
+	chartHeight = 80
+)
+
+// newChart returns chart of points sorted by date.
+func newChart(title string, points []point) chart {
+	c := chart{Title: title, Width: chartWidth, Height: chartHeight, NoData: len(points) == 0}
+	if c.NoData {
+		return c
+	}
+	first, last := points[0].Date, points[len(points)-1].Date
+	for _, p := range points {
+		c.Max = max(c.Max, p.Value)
+	}
+	if len(points) == 1 {
+		// Draw a single value as a horizontal line.
+		points = append(points, point{Date: first.Add(time.Nanosecond), Value: points[0].Value})
+		last = points[1].Date
+	}
+	var coords []string
+	for _, p := range points {
+		x := float64(p.Date.Sub(first)) / float64(last.Sub(first)) * chartWidth
+		y := float64(chartHeight)
+		if c.Max > 0 {
+			y -= float64(p.Value) / float64(c.Max) * chartHeight
+		}
+		coords = append(coords, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	c.Points = strings.Join(coords, " ")
+	c.First = first.Format("2006-01-02")
+	c.Last = last.Format("2006-01-02")
+	return c
+}
+
+// dailyPoints returns a point per calendar day of days.
+func dailyPoints(days []Day, value func(Day) int) []point {
+	var points []point
+	for i, v := range daily(days, 0, value) {
+		points = append(points, point{Date: days[0].Date.Add(time.Duration(i) * 24 * time.Hour), Value: v})
+	}
+	return points
+}
+
+// snapshotPoints returns points of days having a snapshot.
+func snapshotPoints(days []Day, value func(Day) int) []point {
+	var points []point
+	for _, d := range days {
+		if v := value(d); v > 0 {
+			points = append(points, point{Date: d.Date, Value: v})
+		}
+	}
+	return points
+}
+
+// charts returns charts of visitors, cloners, stars and release downloads of
+// s over time.
+func (s Stat) charts() []chart {
+	days := s.days()
+	return []chart{
+		newChart("Unique visitors", dailyPoints(days, uniqueVisitors)),
+		newChart("Unique cloners", dailyPoints(days, uniqueCloners)),
+		newChart("Stars", snapshotPoints(days, func(d Day) int { return d.Stars })),
+		newChart("Release downloads", snapshotPoints(days, func(d Day) int { return d.Downloads })),
+	}
+}
+
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>GitHub repository statistics</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #24292f; }
+table { border-collapse: collapse; }
+th, td { padding: 0.3em 0.8em; border-bottom: 1px solid #d0d7de; text-align: left; }
+td.number, th.number { text-align: right; }
+tfoot td { font-weight: bold; }
+.charts { display: flex; flex-wrap: wrap; gap: 1.5em; }
+figure { margin: 0; }
+figcaption { font-size: 0.9em; margin-bottom: 0.3em; }
+svg { background: #f6f8fa; overflow: visible; }
+polyline { fill: none; stroke: #0969da; stroke-width: 2; }
+.axis { display: flex; justify-content: space-between; font-size: 0.75em; color: #57606a; }
+</style>
+</head>
+<body>
+<h1>GitHub repository statistics</h1>
+<p>Generated {{.Generated}}.</p>
+<table>
+<thead><tr>{{range .Header}}<th{{if .Number}} class="number"{{end}}>{{.Text}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td{{if .Number}} class="number"{{end}}>{{.Text}}</td>{{end}}</tr>
+{{end}}</tbody>
+<tfoot><tr>{{range .Footer}}<td class="number">{{.}}</td>{{end}}</tr></tfoot>
+</table>
+{{range .Repos}}
+<h2>{{.Name}}</h2>
+<div class="charts">
+{{range .Charts}}<figure>
+<figcaption>{{.Title}}{{if not .NoData}} (max {{.Max}}){{end}}</figcaption>
+{{if .NoData}}<p>No data.</p>{{else}}<svg width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}">
+<polyline points="{{.Points}}"/>
+</svg>
+<div class="axis"><span>{{.First}}</span><span>{{.Last}}</span></div>{{end}}
+</figure>
+{{end}}</div>
+{{end}}
+</body>
+</html>
+`))
+
+// cell is a table cell in HTML report.
+type cell struct {
+	Text   string
+	Number bool
+}
+
+// WriteHTML writes a self-contained HTML report with a table of top N stats
+// with selected columns and charts of traffic, stars and release downloads
+// of each repository over time.
+func (stats Stats) WriteHTML(w io.Writer, topN int, columns []string) error {
+	cols, err := lookupColumns(columns)
+	if err != nil {
+		return err
+	}
+	type repo struct {
+		Name   string
+		Charts []chart
+	}
+	data := struct {
+		Generated string
+		Header    []cell
+		Rows      [][]cell
+		Footer    []string
+		Repos     []repo
+	}{
+		Generated: time.Now().Format("2006-01-02 15:04"),
+	}
+	for _, c := range cols {
+		data.Header = append(data.Header, cell{Text: c.header, Number: c.total})
+	}
+	rows := stats.table(topN, cols)
+	for _, row := range rows {
+		var cells []cell
+		for i, v := range row {
+			cells = append(cells, cell{Text: format(v), Number: cols[i].total})
+		}
+		data.Rows = append(data.Rows, cells)
+	}
+	for _, v := range totals(cols, rows) {
+		data.Footer = append(data.Footer, fmt.Sprint(v))
+	}
+	for n, s := range stats {
+		if n == topN {
+			break
+		}
+		data.Repos = append(data.Repos, repo{Name: s.Owner + "/" + s.Repository, Charts: s.charts()})
+	}
+	return htmlReport.Execute(w, data)
+}
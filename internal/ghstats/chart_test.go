@@ -0,0 +1,112 @@
+package ghstats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDailyFillsMissingDays(t *testing.T) {
+	t.Parallel()
+	days := []Day{
+		{Date: day("2023-01-01", 0, 0).Date, UniqueVisitors: 1},
+		{Date: day("2023-01-04", 0, 0).Date, UniqueVisitors: 4},
+	}
+	tests := []struct {
+		n    int
+		want []int
+	}{
+		{n: 0, want: []int{1, 0, 0, 4}},
+		{n: 10, want: []int{1, 0, 0, 4}},
+		{n: 2, want: []int{0, 4}},
+	}
+	for _, test := range tests {
+		got := daily(days, test.n, uniqueVisitors)
+		if !cmp.Equal(test.want, got) {
+			t.Errorf("n=%d: %s", test.n, cmp.Diff(test.want, got))
+		}
+	}
+	if got := daily(nil, 0, uniqueVisitors); got != nil {
+		t.Errorf("want nil for no days, got %v", got)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		values []int
+		want   string
+	}{
+		{values: nil, want: ""},
+		{values: []int{0, 0}, want: "▁▁"},
+		{values: []int{0, 1, 2, 3, 4, 5, 6, 7}, want: "▁▂▃▄▅▆▇█"},
+		{values: []int{10, 5, 0}, want: "█▄▁"},
+	}
+	for _, test := range tests {
+		if got := sparkline(test.values); got != test.want {
+			t.Errorf("%v: want %q, got %q", test.values, test.want, got)
+		}
+	}
+}
+
+func TestNewChart(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		points []point
+		want   chart
+	}{
+		{
+			points: nil,
+			want:   chart{Title: "t", Width: chartWidth, Height: chartHeight, NoData: true},
+		},
+		{
+			points: []point{{Date: day("2023-01-01", 0, 0).Date, Value: 3}},
+			want: chart{Title: "t", Width: chartWidth, Height: chartHeight, Max: 3,
+				Points: "0.0,0.0 320.0,0.0", First: "2023-01-01", Last: "2023-01-01"},
+		},
+		{
+			points: []point{
+				{Date: day("2023-01-01", 0, 0).Date, Value: 0},
+				{Date: day("2023-01-02", 0, 0).Date, Value: 4},
+				{Date: day("2023-01-03", 0, 0).Date, Value: 2},
+			},
+			want: chart{Title: "t", Width: chartWidth, Height: chartHeight, Max: 4,
+				Points: "0.0,80.0 160.0,0.0 320.0,40.0", First: "2023-01-01", Last: "2023-01-03"},
+		},
+	}
+	for _, test := range tests {
+		got := newChart("t", test.points)
+		if !cmp.Equal(test.want, got) {
+			t.Error(cmp.Diff(test.want, got))
+		}
+	}
+}
+
+func TestStatsWriteHTML(t *testing.T) {
+	t.Parallel()
+	stats := Stats{{
+		Owner: "user", Repository: "repo<1>", Stars: 3,
+		History: []Day{
+			{Date: day("2023-01-01", 0, 0).Date, UniqueVisitors: 1, Stars: 2},
+			{Date: day("2023-01-02", 0, 0).Date, UniqueVisitors: 2, Stars: 3},
+		},
+	}}
+	var buf bytes.Buffer
+	if err := stats.WriteHTML(&buf, 10, []string{"repository", "stars"}); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"<h2>user/repo&lt;1&gt;</h2>",
+		`<td class="number">3</td>`,
+		`<polyline points="0.0,40.0 320.0,0.0"/>`, // visitors
+		"Release downloads",
+		"No data.", // cloners, downloads
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want HTML containing %q", want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package ghstats
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// column describes how to print and sort a Stat field.
+type column struct {
+	name    string // used to select the column
+	header  string
+	value   func(Stat) any
+	compare func(x, y Stat) int // ascending order
+	desc    bool                // sort in descending order by default
+	err     func(Stat) error    // error getting the value, may be nil
+	total   bool                // print sum of values in footer
+}
+
+// DefaultColumns are printed when no columns are selected.
+var DefaultColumns = []string{"owner", "repository", "pushed", "stars", "visitors", "cloners", "downloads"}
+
+// TextColumns are added to DefaultColumns in outputs read by humans, i.e.
+// table and markdown.
+var TextColumns = []string{"sparkline"}
+
+// HistoryColumns show data from History.
+var HistoryColumns = []string{"since", "views", "clones", "trend"}
+
+var columns = []column{
+	stringColumn("owner", "Owner", func(s Stat) string { return s.Owner }, nil),
+	stringColumn("repository", "Repository", func(s Stat) string { return s.Repository }, nil),
+	timeColumn("pushed", "Pushed", func(s Stat) time.Time { return s.Pushed }, true, nil),
+	intColumn("stars", "Stars", func(s Stat) int { return s.Stars }, nil),
+	intColumn("forks", "Forks", func(s Stat) int { return s.Forks }, nil),
+	intColumn("watchers", "Watchers", func(s Stat) int { return s.Watchers }, countsErr),
+	intColumn("issues", "Open issues", func(s Stat) int { return s.OpenIssues }, countsErr),
+	intColumn("prs", "Open PRs", func(s Stat) int { return s.OpenPRs }, countsErr),
+	intColumn("contributors", "Contributors", func(s Stat) int { return s.Contributors }, countsErr),
+	intColumn("visitors", "Visitors (2w)", func(s Stat) int { return s.UniqueVisitors }, func(s Stat) error { return s.ViewsErr }),
+	intColumn("cloners", "Cloners (2w)", func(s Stat) int { return s.UniqueCloners }, func(s Stat) error { return s.ClonesErr }),
+	intColumn("downloads", "Release downloads", func(s Stat) int { return s.ReleaseDownloads }, releasesErr),
+	stringColumn("release", "Last release", func(s Stat) string { return s.LastRelease }, releasesErr),
+	timeColumn("released", "Released", func(s Stat) time.Time { return s.LastReleased }, true, releasesErr),
+	intColumn("stargrowth", "Star growth (2w)", func(s Stat) int { return s.StarGrowth }, func(s Stat) error { return s.StarGrowthErr }),
+	stringColumn("referrers", "Top referrers (2w)", func(s Stat) string { return formatPopular(s.Referrers) }, popularErr),
+	stringColumn("paths", "Popular paths (2w)", func(s Stat) string { return formatPopular(s.Paths) }, popularErr),
+	timeColumn("since", "Since", func(s Stat) time.Time { return s.Since }, false, nil),
+	intColumn("views", "Views", func(s Stat) int { return s.TotalViews }, nil),
+	intColumn("clones", "Clones", func(s Stat) int { return s.TotalClones }, nil),
+	{
+		name:    "trend",
+		header:  "Trend (2w)",
+		value:   func(s Stat) any { return fmt.Sprintf("%+d%%", s.ViewsTrend) },
+		compare: func(x, y Stat) int { return cmp.Compare(x.ViewsTrend, y.ViewsTrend) },
+		desc:    true,
+	},
+	{
+		name:    "sparkline",
+		header:  "Visitors (daily)",
+		value:   func(s Stat) any { return sparkline(visitorsDaily(s)) },
+		compare: func(x, y Stat) int { return cmp.Compare(sum(visitorsDaily(x)), sum(visitorsDaily(y))) },
+		desc:    true,
+	},
+}
+
+// columnMetrics are metrics needed by columns. Other columns need none.
+// History columns need traffic to include the latest days.
+var columnMetrics = map[string]Metric{
+	"watchers":     MetricCounts,
+	"issues":       MetricCounts,
+	"prs":          MetricCounts,
+	"contributors": MetricCounts,
+	"visitors":     MetricTraffic,
+	"cloners":      MetricTraffic,
+	"sparkline":    MetricTraffic,
+	"since":        MetricTraffic,
+	"views":        MetricTraffic,
+	"clones":       MetricTraffic,
+	"trend":        MetricTraffic,
+	"downloads":    MetricReleases,
+	"release":      MetricReleases,
+	"released":     MetricReleases,
+	"stargrowth":   MetricStarGrowth,
+	"referrers":    MetricPopular,
+	"paths":        MetricPopular,
+}
+
+// ColumnMetrics returns metrics to fetch for columns or sort keys with
+// names.
+func ColumnMetrics(names []string) (Metric, error) {
+	var metrics Metric
+	for _, name := range names {
+		name, _, _ = strings.Cut(name, ":")
+		if _, err := lookupColumn(name); err != nil {
+			return 0, err
+		}
+		metrics |= columnMetrics[name]
+	}
+	return metrics, nil
+}
+
+// ColumnNames returns names of all columns.
+func ColumnNames() []string {
+	var names []string
+	for _, c := range columns {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// lookupColumn returns column with name.
+func lookupColumn(name string) (column, error) {
+	for _, c := range columns {
+		if c.name == name {
+			return c, nil
+		}
+	}
+	return column{}, fmt.Errorf("unknown column %q, use one of %s", name, strings.Join(ColumnNames(), ", "))
+}
+
+// intColumn returns a column sorted from the highest number by default.
+func intColumn(name, header string, v func(Stat) int, err func(Stat) error) column {
+	return column{
+		name:    name,
+		header:  header,
+		value:   func(s Stat) any { return v(s) },
+		compare: func(x, y Stat) int { return cmp.Compare(v(x), v(y)) },
+		desc:    true,
+		err:     err,
+		total:   true,
+	}
+}
+
+// stringColumn returns a column sorted alphabetically by default.
+func stringColumn(name, header string, v func(Stat) string, err func(Stat) error) column {
+	return column{
+		name:    name,
+		header:  header,
+		value:   func(s Stat) any { return v(s) },
+		compare: func(x, y Stat) int { return strings.Compare(v(x), v(y)) },
+		err:     err,
+	}
+}
+
+// timeColumn returns a column with dates sorted from the newest or the oldest
+// by default.
+func timeColumn(name, header string, v func(Stat) time.Time, newestFirst bool, err func(Stat) error) column {
+	return column{
+		name:   name,
+		header: header,
+		value: func(s Stat) any {
+			if v(s).IsZero() {
+				return ""
+			}
+			return v(s).Format("2006-01-02")
+		},
+		compare: func(x, y Stat) int { return v(x).Compare(v(y)) },
+		desc:    newestFirst,
+		err:     err,
+	}
+}
+
+// lookupColumns returns columns with names.
+func lookupColumns(names []string) ([]column, error) {
+	var cols []column
+	for _, name := range names {
+		col, err := lookupColumn(name)
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("no columns selected")
+	}
+	return cols, nil
+}
+
+func sum(values []int) int {
+	var n int
+	for _, v := range values {
+		n += v
+	}
+	return n
+}
+
+func countsErr(s Stat) error   { return s.CountsErr }
+func releasesErr(s Stat) error { return s.ReleasesErr }
+func popularErr(s Stat) error  { return s.PopularErr }
+
+// formatPopular formats top three referrers or paths.
+func formatPopular(popular []Popular) string {
+	var top []string
+	for i, p := range popular {
+		if i == 3 {
+			break
+		}
+		top = append(top, fmt.Sprintf("%s (%d)", p.Name, p.Count))
+	}
+	return strings.Join(top, ", ")
+}
@@ -2,95 +2,244 @@ package ghstats
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
-	"sort"
-	"text/tabwriter"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v50/github"
-	"golang.org/x/oauth2"
 )
 
 type Stat struct {
+	Owner            string
 	Repository       string
 	Pushed           time.Time
 	Stars            int
+	Forks            int
+	Watchers         int
+	OpenIssues       int
+	OpenPRs          int
+	Contributors     int
 	UniqueVisitors   int
 	UniqueCloners    int
 	ReleaseDownloads int
+	LastRelease      string // tag name
+	LastReleased     time.Time
+	StarGrowth       int       // new stars in the last two weeks
+	Referrers        []Popular // top referrers in the last two weeks
+	Paths            []Popular // popular paths in the last two weeks
+	Traffic          []Day     // daily traffic in the last two weeks
+	Fetched          time.Time
+	Metrics          Metric // details fetched; fields of others are zero
+
+	// Fields set from History.
+	History     []Day
+	Since       time.Time // first day in history
+	TotalViews  int
+	TotalClones int
+	ViewsTrend  int // percentual change of views in the last two weeks
+
+	// Errors getting repository details; corresponding fields are zero.
+	ViewsErr      error
+	ClonesErr     error
+	ReleasesErr   error
+	CountsErr     error // Watchers, OpenIssues, OpenPRs, Contributors
+	PopularErr    error // Referrers, Paths
+	StarGrowthErr error
+}
+
+// Err returns all errors getting the stat, or nil if it's complete.
+func (s Stat) Err() error {
+	return errors.Join(s.ViewsErr, s.ClonesErr, s.ReleasesErr, s.CountsErr, s.PopularErr, s.StarGrowthErr)
 }
 
 type Stats []Stat
 
-func Get(user, token string) (Stats, error) {
-	ctx := context.Background()
-	client := getClient(ctx, token)
+// Filter selects which repositories to include.
+type Filter struct {
+	Forks    bool
+	Archived bool
+	Private  bool
+}
 
-	repos, err := getAllRepos(ctx, client, user)
-	if err != nil {
-		return nil, err
+// include reports whether repository r passes the filter.
+func (f Filter) include(r *github.Repository) bool {
+	switch {
+	case r.GetFork() && !f.Forks:
+		return false
+	case r.GetArchived() && !f.Archived:
+		return false
+	case r.GetPrivate() && !f.Private:
+		return false
 	}
+	return true
+}
 
-	ch := make(chan Stat)
+// Metric is a group of repository details that costs extra API calls per
+// repository. Other details come with the listing of repositories.
+type Metric uint
 
-	for _, r := range repos {
-		go func(r *github.Repository) {
-			views, _, err := client.Repositories.ListTrafficViews(ctx, user, r.GetName(), nil)
-			if err != nil {
-				log.Print(err)
-			}
+const (
+	MetricTraffic    Metric = 1 << iota // visitors, cloners and daily traffic
+	MetricReleases                      // release downloads and last release
+	MetricCounts                        // watchers, open issues and PRs, contributors
+	MetricPopular                       // top referrers and popular paths
+	MetricStarGrowth                    // new stars
 
-			clones, _, err := client.Repositories.ListTrafficClones(ctx, user, *r.Name, nil)
-			if err != nil {
-				log.Print(err)
-			}
+	AllMetrics = MetricTraffic | MetricReleases | MetricCounts | MetricPopular | MetricStarGrowth
+)
 
-			var releaseDownloads int
-			releases, _, err := client.Repositories.ListReleases(ctx, user, r.GetName(), nil)
-			if err != nil {
-				log.Print(err)
-			}
-			for _, r := range releases {
-				for _, a := range r.Assets {
-					releaseDownloads += *a.DownloadCount
-				}
+// Options control which repositories are included and how they are fetched.
+type Options struct {
+	Filter
+	Concurrency int    // maximum number of repositories fetched at once
+	Metrics     Metric // details to fetch, see ColumnMetrics
+}
+
+// Get returns stats for repositories of owners, i.e. users or organisations.
+// Empty owner means the authenticated user. Errors getting repository details
+// are stored in Stat. If ctx is canceled, stats fetched so far are returned
+// with ctx's error.
+func Get(ctx context.Context, client *github.Client, owners []string, opts Options) (Stats, error) {
+	var repos []*github.Repository
+	seen := make(map[string]bool)
+	for _, owner := range owners {
+		ownerRepos, err := getAllRepos(ctx, client, owner)
+		if err != nil {
+			return nil, fmt.Errorf("listing repositories of %q: %w", owner, err)
+		}
+		for _, r := range ownerRepos {
+			if seen[r.GetFullName()] || !opts.Filter.include(r) {
+				continue
 			}
+			seen[r.GetFullName()] = true
+			repos = append(repos, r)
+		}
+	}
 
-			stat := Stat{
-				Repository:       r.GetName(),
-				Pushed:           r.PushedAt.Time,
-				Stars:            r.GetStargazersCount(),
-				UniqueVisitors:   views.GetUniques(),
-				UniqueCloners:    clones.GetUniques(),
-				ReleaseDownloads: releaseDownloads,
+	in := make(chan *github.Repository)
+	out := make(chan Stat)
+
+	var wg sync.WaitGroup
+	for i := 0; i < max(opts.Concurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			for r := range in {
+				out <- getStat(ctx, client, r, opts.Metrics)
 			}
-			ch <- stat
-		}(r)
+			wg.Done()
+		}()
 	}
 
+	go func() {
+	loop:
+		for _, r := range repos {
+			select {
+			case in <- r:
+			case <-ctx.Done():
+				break loop
+			}
+		}
+		close(in)
+		wg.Wait()
+		close(out)
+	}()
+
 	var stats Stats
-	for range repos {
-		stats = append(stats, <-ch)
+	for s := range out {
+		if ctx.Err() != nil && s.Err() != nil {
+			continue // incomplete because of cancellation
+		}
+		stats = append(stats, s)
 	}
 
-	return stats, nil
+	return stats, ctx.Err()
 }
 
-func getClient(ctx context.Context, token string) *github.Client {
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	hc := oauth2.NewClient(ctx, ts)
-	return github.NewClient(hc)
+// getStat gets stat of repository r with details of metrics.
+func getStat(ctx context.Context, client *github.Client, r *github.Repository, metrics Metric) Stat {
+	owner := r.GetOwner().GetLogin()
+	stat := Stat{
+		Owner:      owner,
+		Repository: r.GetName(),
+		Pushed:     r.GetPushedAt().Time,
+		Stars:      r.GetStargazersCount(),
+		Forks:      r.GetForksCount(),
+		Fetched:    time.Now(),
+		Metrics:    metrics,
+	}
+
+	if metrics&MetricTraffic != 0 {
+		var views *github.TrafficViews
+		stat.ViewsErr = call(ctx, func() (resp *github.Response, err error) {
+			views, resp, err = client.Repositories.ListTrafficViews(ctx, owner, r.GetName(), nil)
+			return resp, err
+		})
+
+		var clones *github.TrafficClones
+		stat.ClonesErr = call(ctx, func() (resp *github.Response, err error) {
+			clones, resp, err = client.Repositories.ListTrafficClones(ctx, owner, r.GetName(), nil)
+			return resp, err
+		})
+
+		stat.UniqueVisitors = views.GetUniques()
+		stat.UniqueCloners = clones.GetUniques()
+		stat.Traffic = trafficDays(views, clones)
+	}
+
+	if metrics&MetricReleases != 0 {
+		var releases []*github.RepositoryRelease
+		stat.ReleasesErr = call(ctx, func() (resp *github.Response, err error) {
+			releases, resp, err = client.Repositories.ListReleases(ctx, owner, r.GetName(), nil)
+			return resp, err
+		})
+		for _, r := range releases {
+			for _, a := range r.Assets {
+				stat.ReleaseDownloads += a.GetDownloadCount()
+			}
+		}
+		setLastRelease(releases, &stat)
+	}
+
+	if metrics&MetricCounts != 0 {
+		stat.CountsErr = getCounts(ctx, client, owner, stat.Repository, &stat)
+	}
+	if metrics&MetricPopular != 0 {
+		stat.PopularErr = getPopular(ctx, client, owner, stat.Repository, &stat)
+	}
+	if metrics&MetricStarGrowth != 0 && stat.Stars > 0 {
+		stat.StarGrowthErr = getStarGrowth(ctx, client, owner, stat.Repository, &stat)
+	}
+	return stat
 }
 
-// getAllRepos returns all user's repositories. Set user to empty string for
-// repositories of the authenticated user.
-func getAllRepos(ctx context.Context, ghc *github.Client, user string) ([]*github.Repository, error) {
+// getAllRepos returns all repositories of owner which is a user or an
+// organisation. Set owner to empty string for repositories of the
+// authenticated user.
+func getAllRepos(ctx context.Context, ghc *github.Client, owner string) ([]*github.Repository, error) {
+	if owner != "" {
+		var user *github.User
+		err := call(ctx, func() (resp *github.Response, err error) {
+			user, resp, err = ghc.Users.Get(ctx, owner)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if user.GetType() == "Organization" {
+			return getAllOrgRepos(ctx, ghc, owner)
+		}
+	}
+
 	opt := &github.RepositoryListOptions{ListOptions: github.ListOptions{PerPage: 10}}
 	var allRepos []*github.Repository
 	for {
-		repos, resp, err := ghc.Repositories.List(ctx, user, opt)
+		var repos []*github.Repository
+		var resp *github.Response
+		err := call(ctx, func() (_ *github.Response, err error) {
+			repos, resp, err = ghc.Repositories.List(ctx, owner, opt)
+			return resp, err
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -103,79 +252,25 @@ func getAllRepos(ctx context.Context, ghc *github.Client, user string) ([]*githu
 	return allRepos, nil
 }
 
-func (stats Stats) Sort(column int) {
-	sort.Sort(customSort{stats: stats, less: func(x, y Stat) bool {
-		switch column {
-		case 1:
-			if x.Repository != y.Repository {
-				return x.Repository < y.Repository
-			}
-		case 2:
-			if x.Pushed != y.Pushed {
-				return x.Pushed.After(y.Pushed)
-			}
-		case 3:
-			if x.Stars != y.Stars {
-				return x.Stars > y.Stars
-			}
-		case 4:
-			if x.UniqueVisitors != y.UniqueVisitors {
-				return x.UniqueVisitors > y.UniqueVisitors
-			}
-		case 5:
-			if x.UniqueCloners != y.UniqueCloners {
-				return x.UniqueCloners > y.UniqueCloners
-			}
-		case 6:
-			if x.ReleaseDownloads != y.ReleaseDownloads {
-				return x.ReleaseDownloads > y.ReleaseDownloads
-			}
-		default:
-			log.Fatalf("can't sort by column %d", column)
-		}
-		if x.Repository != y.Repository {
-			return x.Repository < y.Repository
+// getAllOrgRepos returns all repositories of organisation org.
+func getAllOrgRepos(ctx context.Context, ghc *github.Client, org string) ([]*github.Repository, error) {
+	opt := &github.RepositoryListByOrgOptions{Type: "all", ListOptions: github.ListOptions{PerPage: 10}}
+	var allRepos []*github.Repository
+	for {
+		var repos []*github.Repository
+		var resp *github.Response
+		err := call(ctx, func() (_ *github.Response, err error) {
+			repos, resp, err = ghc.Repositories.ListByOrg(ctx, org, opt)
+			return resp, err
+		})
+		if err != nil {
+			return nil, err
 		}
-		return false
-	}})
-}
-
-type customSort struct {
-	stats []Stat
-	less  func(x, y Stat) bool
-}
-
-func (x customSort) Len() int           { return len(x.stats) }
-func (x customSort) Less(i, j int) bool { return x.less(x.stats[i], x.stats[j]) }
-func (x customSort) Swap(i, j int)      { x.stats[i], x.stats[j] = x.stats[j], x.stats[i] }
-
-func (stats Stats) Print(topN int) {
-	const format = "%v\t%v\t%v\t%v\t%v\t%v\n"
-	tw := new(tabwriter.Writer).Init(os.Stdout, 0, 8, 2, ' ', 0)
-	fmt.Fprintf(tw, format, "Repository", "Pushed", "Stars", "Visitors (2w)", "Cloners (2w)", "Release downloads")
-	fmt.Fprintf(tw, format, "----------", "------", "-----", "-------------", "------------", "-----------------")
-	var n int
-	var total struct {
-		stars     int
-		visitors  int
-		cloners   int
-		downloads int
-	}
-	for _, s := range stats {
-		if n == topN {
+		allRepos = append(allRepos, repos...)
+		if resp.NextPage == 0 {
 			break
 		}
-		n++
-		total.stars += s.Stars
-		total.visitors += s.UniqueVisitors
-		total.cloners += s.UniqueCloners
-		total.downloads += s.ReleaseDownloads
-		fmt.Fprintf(tw, format, s.Repository, s.Pushed.Format("2006-01-02"), s.Stars, s.UniqueVisitors, s.UniqueCloners, s.ReleaseDownloads)
+		opt.Page = resp.NextPage
 	}
-
-	// Print footer.
-	fmt.Fprintf(tw, format, "          ", "      ", "-----", "-------------", "------------", "-----------------")
-	fmt.Fprintf(tw, format, "", "", total.stars, total.visitors, total.cloners, total.downloads)
-
-	tw.Flush()
+	return allRepos, nil
 }
@@ -0,0 +1,322 @@
+package ghstats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-github/v50/github"
+)
+
+// fakeAPI is a fake GitHub API serving repositories of a single user or
+// organisation.
+type fakeAPI struct {
+	user    string
+	org     bool // user is an organisation
+	repos   int  // number of repositories named repo1, repo2, ...
+	perPage int
+	fields  map[string]map[string]any // additional fields of repositories by name
+	failing map[string]bool           // paths returning server error
+	visited func(path string)
+}
+
+// newClient starts fake API server and returns client using it.
+func newClient(t *testing.T, api fakeAPI) *github.Client {
+	t.Helper()
+	srv := httptest.NewServer(api.handler())
+	t.Cleanup(srv.Close)
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+	return client
+}
+
+func (api fakeAPI) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{user}", func(w http.ResponseWriter, r *http.Request) {
+		typ := "User"
+		if api.org {
+			typ = "Organization"
+		}
+		writeJSON(w, map[string]any{"login": r.PathValue("user"), "type": typ})
+	})
+	listRepos := func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+		lastPage := (api.repos + api.perPage - 1) / api.perPage
+		if page < lastPage {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=%d>; rel="next", <http://%s%s?page=%d>; rel="last"`,
+				r.Host, r.URL.Path, page+1, r.Host, r.URL.Path, lastPage))
+		}
+		var repos []map[string]any
+		for i := (page-1)*api.perPage + 1; i <= min(page*api.perPage, api.repos); i++ {
+			name := fmt.Sprintf("repo%d", i)
+			repo := map[string]any{
+				"name":             name,
+				"full_name":        api.user + "/" + name,
+				"owner":            map[string]any{"login": api.user},
+				"stargazers_count": i,
+				"forks_count":      1,
+				"pushed_at":        "2023-01-01T00:00:00Z",
+			}
+			for k, v := range api.fields[name] {
+				repo[k] = v
+			}
+			repos = append(repos, repo)
+		}
+		writeJSON(w, repos)
+	}
+	mux.HandleFunc("GET /user/repos", listRepos) // of authenticated user
+	if api.org {
+		mux.HandleFunc("GET /orgs/{org}/repos", listRepos)
+	} else {
+		mux.HandleFunc("GET /users/{user}/repos", listRepos)
+	}
+	mux.HandleFunc("GET /repos/{user}/{repo}/traffic/views", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"count": 3, "uniques": 2,
+			"views": []map[string]any{
+				{"timestamp": "2023-01-01T00:00:00Z", "count": 1, "uniques": 1},
+				{"timestamp": "2023-01-02T00:00:00Z", "count": 2, "uniques": 1},
+			},
+		})
+	})
+	mux.HandleFunc("GET /repos/{user}/{repo}/traffic/clones", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"count": 1, "uniques": 1,
+			"clones": []map[string]any{
+				{"timestamp": "2023-01-02T00:00:00Z", "count": 1, "uniques": 1},
+			},
+		})
+	})
+	mux.HandleFunc("GET /repos/{user}/{repo}/releases", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]any{
+			{"tag_name": "v1.1.0", "published_at": "2023-01-02T00:00:00Z", "assets": []map[string]any{{"download_count": 10}, {"download_count": 5}}},
+			{"tag_name": "v1.0.0", "published_at": "2023-01-01T00:00:00Z", "assets": []map[string]any{{"download_count": 1}}},
+			{"tag_name": "v2.0.0", "draft": true},
+		})
+	})
+	mux.HandleFunc("GET /repos/{user}/{repo}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"subscribers_count": 4, "open_issues_count": 5})
+	})
+	mux.HandleFunc("GET /repos/{user}/{repo}/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=2>; rel="next", <http://%s%s?page=2>; rel="last"`,
+			r.Host, r.URL.Path, r.Host, r.URL.Path))
+		writeJSON(w, []map[string]any{{"number": 1}})
+	})
+	mux.HandleFunc("GET /repos/{user}/{repo}/contributors", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]any{{"login": "someone"}})
+	})
+	mux.HandleFunc("GET /repos/{user}/{repo}/traffic/popular/referrers", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]any{{"referrer": "github.com", "count": 3, "uniques": 2}})
+	})
+	mux.HandleFunc("GET /repos/{user}/{repo}/traffic/popular/paths", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]any{{"path": "/readme", "count": 2, "uniques": 1}})
+	})
+	mux.HandleFunc("GET /repos/{user}/{repo}/stargazers", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]any{
+			{"starred_at": time.Now().Add(-30 * 24 * time.Hour).Format(time.RFC3339)},
+			{"starred_at": time.Now().Add(-time.Hour).Format(time.RFC3339)},
+		})
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if api.visited != nil {
+			api.visited(r.URL.Path)
+		}
+		if api.failing[r.URL.Path] {
+			http.Error(w, `{"message": "server error"}`, http.StatusInternalServerError)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestGetAllReposPaginates(t *testing.T) {
+	t.Parallel()
+	client := newClient(t, fakeAPI{user: "user", repos: 25, perPage: 10})
+	repos, err := getAllRepos(context.Background(), client, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 25 {
+		t.Errorf("want 25 repos, got %d", len(repos))
+	}
+}
+
+func TestGetAllReposListsOrganisationRepos(t *testing.T) {
+	t.Parallel()
+	client := newClient(t, fakeAPI{user: "org", org: true, repos: 15, perPage: 10}) // no /users/org/repos
+	repos, err := getAllRepos(context.Background(), client, "org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 15 {
+		t.Errorf("want 15 repos, got %d", len(repos))
+	}
+}
+
+func TestGetAggregatesTrafficAndReleases(t *testing.T) {
+	t.Parallel()
+	client := newClient(t, fakeAPI{user: "user", repos: 1, perPage: 10})
+	stats, err := Get(context.Background(), client, []string{"user"}, Options{Concurrency: 2, Metrics: AllMetrics})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("want 1 stat, got %d", len(stats))
+	}
+	got := stats[0]
+	want := Stat{
+		Owner:            "user",
+		Repository:       "repo1",
+		Pushed:           time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Stars:            1,
+		Forks:            1,
+		Watchers:         4,
+		OpenIssues:       3,
+		OpenPRs:          2,
+		Contributors:     1,
+		UniqueVisitors:   2,
+		UniqueCloners:    1,
+		ReleaseDownloads: 16,
+		LastRelease:      "v1.1.0",
+		LastReleased:     time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		StarGrowth:       1,
+		Referrers:        []Popular{{Name: "github.com", Count: 3, Uniques: 2}},
+		Paths:            []Popular{{Name: "/readme", Count: 2, Uniques: 1}},
+		Metrics:          AllMetrics,
+		Traffic: []Day{
+			{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Views: 1, UniqueVisitors: 1},
+			{Date: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), Views: 2, UniqueVisitors: 1, Clones: 1, UniqueCloners: 1},
+		},
+	}
+	opts := cmp.Options{cmpopts.EquateEmpty(), cmpopts.IgnoreFields(Stat{}, "Fetched")}
+	if !cmp.Equal(want, got, opts) {
+		t.Error(cmp.Diff(want, got, opts))
+	}
+	if got.Fetched.IsZero() {
+		t.Error("want Fetched set")
+	}
+}
+
+func TestGetStoresPerRepoErrors(t *testing.T) {
+	t.Parallel()
+	client := newClient(t, fakeAPI{user: "user", repos: 2, perPage: 10, failing: map[string]bool{
+		"/repos/user/repo2/traffic/clones": true,
+	}})
+	stats, err := Get(context.Background(), client, []string{"user"}, Options{Concurrency: 1, Metrics: AllMetrics})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stats.Sort("repository"); err != nil {
+		t.Fatal(err)
+	}
+	if err := stats[0].Err(); err != nil {
+		t.Errorf("repo1: want no error, got %v", err)
+	}
+	if stats[1].ClonesErr == nil {
+		t.Errorf("repo2: want clones error, got nil")
+	}
+	if stats[1].ViewsErr != nil {
+		t.Errorf("repo2: want no views error, got %v", stats[1].ViewsErr)
+	}
+}
+
+func TestGetFetchesOnlySelectedMetrics(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var paths []string
+	client := newClient(t, fakeAPI{user: "user", repos: 2, perPage: 10, visited: func(path string) {
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, path)
+	}})
+	metrics, err := ColumnMetrics([]string{"repository", "stars", "release", "visitors:asc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := Get(context.Background(), client, []string{"user"}, Options{Concurrency: 1, Metrics: metrics})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stats.Sort("repository"); err != nil {
+		t.Fatal(err)
+	}
+	if got := stats[0]; got.LastRelease != "v1.1.0" || got.UniqueVisitors != 2 || got.Watchers != 0 || got.Referrers != nil {
+		t.Errorf("got %+v", got)
+	}
+
+	slices.Sort(paths)
+	want := []string{
+		"/repos/user/repo1/releases",
+		"/repos/user/repo1/traffic/clones",
+		"/repos/user/repo1/traffic/views",
+		"/repos/user/repo2/releases",
+		"/repos/user/repo2/traffic/clones",
+		"/repos/user/repo2/traffic/views",
+		"/users/user",
+		"/users/user/repos",
+	}
+	if !cmp.Equal(want, paths) {
+		t.Error(cmp.Diff(want, paths))
+	}
+}
+
+func TestGetFiltersRepos(t *testing.T) {
+	t.Parallel()
+	client := newClient(t, fakeAPI{user: "user", repos: 4, perPage: 10, fields: map[string]map[string]any{
+		"repo1": {"fork": true},
+		"repo2": {"archived": true},
+		"repo3": {"private": true},
+	}})
+	testcases := []struct {
+		filter Filter
+		want   []string
+	}{
+		{Filter{}, []string{"repo4"}},
+		{Filter{Forks: true}, []string{"repo1", "repo4"}},
+		{Filter{Archived: true, Private: true}, []string{"repo2", "repo3", "repo4"}},
+	}
+	for _, tc := range testcases {
+		stats, err := Get(context.Background(), client, []string{"user"}, Options{Filter: tc.filter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, s := range stats {
+			got = append(got, s.Repository)
+		}
+		slices.Sort(got)
+		if !cmp.Equal(tc.want, got) {
+			t.Errorf("%+v: %s", tc.filter, cmp.Diff(tc.want, got))
+		}
+	}
+}
+
+func TestGetDeduplicatesReposOfOwners(t *testing.T) {
+	t.Parallel()
+	client := newClient(t, fakeAPI{user: "user", repos: 3, perPage: 10})
+	// Authenticated user's repositories include those of user.
+	stats, err := Get(context.Background(), client, []string{"", "user"}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 3 {
+		t.Errorf("want 3 stats, got %d", len(stats))
+	}
+}
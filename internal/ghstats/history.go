@@ -0,0 +1,178 @@
+package ghstats
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// Day is repository traffic during a single day.
+type Day struct {
+	Date           time.Time `json:"date"`
+	Views          int       `json:"views"`
+	UniqueVisitors int       `json:"uniqueVisitors"`
+	Clones         int       `json:"clones"`
+	UniqueCloners  int       `json:"uniqueCloners"`
+
+	// Snapshots of repository counters taken on the day stats were fetched.
+	// Zero means no snapshot.
+	Stars     int `json:"stars,omitempty"`
+	Downloads int `json:"downloads,omitempty"`
+}
+
+// History is daily traffic of repositories keyed by owner/repository. GitHub
+// keeps traffic data only for 14 days so it needs to be stored to see
+// long-term totals and trends.
+type History map[string][]Day
+
+// LoadHistory loads history from a JSON file. Non-existent file means empty
+// history.
+func LoadHistory(file string) (History, error) {
+	h := make(History)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Save saves history to a JSON file.
+func (h History) Save(file string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+// Merge adds traffic of stats to history and sets historical fields of stats.
+// Overlapping days are merged by taking the higher numbers because the
+// current day's traffic is incomplete. Stars and, if fetched, release
+// downloads of stats with Fetched set are recorded as snapshots of that day.
+func (h History) Merge(stats Stats) {
+	for i := range stats {
+		s := &stats[i]
+		key := s.Owner + "/" + s.Repository
+
+		days := make(map[time.Time]Day)
+		for _, d := range h[key] {
+			days[d.Date] = d
+		}
+		for _, d := range s.Traffic {
+			old := days[d.Date]
+			days[d.Date] = Day{
+				Date:           d.Date,
+				Views:          max(old.Views, d.Views),
+				UniqueVisitors: max(old.UniqueVisitors, d.UniqueVisitors),
+				Clones:         max(old.Clones, d.Clones),
+				UniqueCloners:  max(old.UniqueCloners, d.UniqueCloners),
+				Stars:          old.Stars,
+				Downloads:      old.Downloads,
+			}
+		}
+		if !s.Fetched.IsZero() {
+			date := s.Fetched.UTC().Truncate(24 * time.Hour)
+			d := days[date]
+			d.Date = date
+			d.Stars = s.Stars
+			if s.Metrics&MetricReleases != 0 && s.ReleasesErr == nil {
+				d.Downloads = s.ReleaseDownloads
+			}
+			days[date] = d
+		}
+
+		var merged []Day
+		for _, d := range days {
+			merged = append(merged, d)
+		}
+		sort.Slice(merged, func(i, j int) bool { return merged[i].Date.Before(merged[j].Date) })
+		if len(merged) > 0 {
+			h[key] = merged
+		}
+
+		s.History = merged
+		s.TotalViews, s.TotalClones = 0, 0
+		for _, d := range merged {
+			s.TotalViews += d.Views
+			s.TotalClones += d.Clones
+		}
+		if len(merged) > 0 {
+			s.Since = merged[0].Date
+		}
+		s.ViewsTrend = viewsTrend(merged)
+	}
+}
+
+// trendWindow is the period whose views are compared with the previous one.
+const trendWindow = 14 * 24 * time.Hour
+
+// viewsTrend returns percentual change of views in the last trendWindow
+// compared to the trendWindow before. It returns 0 if there's not enough data.
+func viewsTrend(days []Day) int {
+	if len(days) == 0 {
+		return 0
+	}
+	last := days[len(days)-1].Date
+	if last.Sub(days[0].Date) < trendWindow {
+		return 0
+	}
+	var recent, previous int
+	for _, d := range days {
+		switch age := last.Sub(d.Date); {
+		case age < trendWindow:
+			recent += d.Views
+		case age < 2*trendWindow:
+			previous += d.Views
+		}
+	}
+	if previous == 0 {
+		return 0
+	}
+	return (recent - previous) * 100 / previous
+}
+
+// trafficDays combines daily views and clones as returned by GitHub API.
+func trafficDays(views *github.TrafficViews, clones *github.TrafficClones) []Day {
+	days := make(map[time.Time]*Day)
+	day := func(ts github.Timestamp) *Day {
+		date := ts.UTC().Truncate(24 * time.Hour)
+		if days[date] == nil {
+			days[date] = &Day{Date: date}
+		}
+		return days[date]
+	}
+	if views == nil {
+		views = new(github.TrafficViews)
+	}
+	if clones == nil {
+		clones = new(github.TrafficClones)
+	}
+	for _, v := range views.Views {
+		d := day(v.GetTimestamp())
+		d.Views = v.GetCount()
+		d.UniqueVisitors = v.GetUniques()
+	}
+	for _, c := range clones.Clones {
+		d := day(c.GetTimestamp())
+		d.Clones = c.GetCount()
+		d.UniqueCloners = c.GetUniques()
+	}
+
+	var traffic []Day
+	for _, d := range days {
+		traffic = append(traffic, *d)
+	}
+	sort.Slice(traffic, func(i, j int) bool { return traffic[i].Date.Before(traffic[j].Date) })
+	return traffic
+}
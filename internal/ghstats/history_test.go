@@ -0,0 +1,100 @@
+package ghstats
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func day(date string, views, clones int) Day {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		panic(err)
+	}
+	return Day{Date: t, Views: views, Clones: clones}
+}
+
+func TestHistoryMergeMergesOverlappingDays(t *testing.T) {
+	h := History{
+		"user/repo": {day("2023-01-01", 1, 1), day("2023-01-02", 2, 0)},
+	}
+	stats := Stats{{
+		Owner:      "user",
+		Repository: "repo",
+		Traffic:    []Day{day("2023-01-02", 5, 1), day("2023-01-03", 3, 3)},
+	}}
+	h.Merge(stats)
+
+	want := []Day{day("2023-01-01", 1, 1), day("2023-01-02", 5, 1), day("2023-01-03", 3, 3)}
+	if !cmp.Equal(want, h["user/repo"]) {
+		t.Error(cmp.Diff(want, h["user/repo"]))
+	}
+	if stats[0].TotalViews != 9 || stats[0].TotalClones != 5 {
+		t.Errorf("want 9 views and 5 clones, got %d and %d", stats[0].TotalViews, stats[0].TotalClones)
+	}
+	if !stats[0].Since.Equal(want[0].Date) {
+		t.Errorf("want since %v, got %v", want[0].Date, stats[0].Since)
+	}
+}
+
+func TestViewsTrend(t *testing.T) {
+	days := []Day{
+		day("2023-01-01", 10, 0), // previous two weeks
+		day("2023-01-14", 10, 0),
+		day("2023-01-15", 10, 0), // last two weeks
+		day("2023-01-28", 20, 0),
+	}
+	if got := viewsTrend(days); got != 50 {
+		t.Errorf("want trend 50%%, got %d%%", got)
+	}
+	if got := viewsTrend(days[2:]); got != 0 {
+		t.Errorf("want trend 0%% for short history, got %d%%", got)
+	}
+}
+
+func TestHistorySaveAndLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history.json")
+	h, err := LoadHistory(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h["user/repo"] = []Day{day("2023-01-01", 1, 1)}
+	if err := h.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadHistory(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(h, got) {
+		t.Error(cmp.Diff(h, got))
+	}
+}
+
+func TestHistoryMergeRecordsSnapshots(t *testing.T) {
+	h := History{
+		"user/repo": {{Date: day("2023-01-01", 0, 0).Date, Stars: 1, Downloads: 5}},
+	}
+	fetched := time.Date(2023, 1, 2, 15, 0, 0, 0, time.UTC)
+	stats := Stats{
+		{Owner: "user", Repository: "repo", Stars: 2, ReleaseDownloads: 7, Fetched: fetched, Metrics: MetricReleases},
+		{Owner: "user", Repository: "other", Stars: 3, ReleasesErr: errors.New("failed"), Fetched: fetched, Metrics: MetricReleases},
+		{Owner: "user", Repository: "unfetched", Stars: 4, Fetched: fetched},
+	}
+	h.Merge(stats)
+
+	want := History{
+		"user/repo": {
+			{Date: day("2023-01-01", 0, 0).Date, Stars: 1, Downloads: 5},
+			{Date: day("2023-01-02", 0, 0).Date, Stars: 2, Downloads: 7},
+		},
+		"user/other":     {{Date: day("2023-01-02", 0, 0).Date, Stars: 3}},
+		"user/unfetched": {{Date: day("2023-01-02", 0, 0).Date, Stars: 4}},
+	}
+	if !cmp.Equal(want, h) {
+		t.Error(cmp.Diff(want, h))
+	}
+}
@@ -0,0 +1,144 @@
+package ghstats
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// Popular is a top referrer or a popular path from repository traffic.
+type Popular struct {
+	Name    string
+	Count   int
+	Uniques int
+}
+
+// growthWindow is the period for which star growth is counted. It's the same
+// as GitHub's traffic window.
+const growthWindow = 14 * 24 * time.Hour
+
+// getCounts sets watchers, open issues and pull requests and contributors
+// count of stat.
+func getCounts(ctx context.Context, client *github.Client, owner, repo string, stat *Stat) error {
+	var r *github.Repository
+	err := call(ctx, func() (resp *github.Response, err error) {
+		r, resp, err = client.Repositories.Get(ctx, owner, repo)
+		return resp, err
+	})
+	if err != nil {
+		return err
+	}
+	stat.Watchers = r.GetSubscribersCount()
+
+	// Count items by requesting one item per page and looking at the
+	// number of pages.
+	var prs []*github.PullRequest
+	var resp *github.Response
+	err = call(ctx, func() (_ *github.Response, err error) {
+		prs, resp, err = client.PullRequests.List(ctx, owner, repo,
+			&github.PullRequestListOptions{State: "open", ListOptions: github.ListOptions{PerPage: 1}})
+		return resp, err
+	})
+	if err != nil {
+		return err
+	}
+	stat.OpenPRs = count(len(prs), resp)
+	stat.OpenIssues = r.GetOpenIssuesCount() - stat.OpenPRs // GitHub counts PRs as issues
+
+	var contributors []*github.Contributor
+	err = call(ctx, func() (_ *github.Response, err error) {
+		contributors, resp, err = client.Repositories.ListContributors(ctx, owner, repo,
+			&github.ListContributorsOptions{Anon: "true", ListOptions: github.ListOptions{PerPage: 1}})
+		return resp, err
+	})
+	if err != nil {
+		return err
+	}
+	stat.Contributors = count(len(contributors), resp)
+	return nil
+}
+
+// count returns the number of items in a list requested with one item per
+// page.
+func count(n int, resp *github.Response) int {
+	if resp != nil && resp.LastPage > 0 {
+		return resp.LastPage
+	}
+	return n
+}
+
+// getPopular sets top referrers and popular paths of stat.
+func getPopular(ctx context.Context, client *github.Client, owner, repo string, stat *Stat) error {
+	var referrers []*github.TrafficReferrer
+	err := call(ctx, func() (resp *github.Response, err error) {
+		referrers, resp, err = client.Repositories.ListTrafficReferrers(ctx, owner, repo)
+		return resp, err
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range referrers {
+		stat.Referrers = append(stat.Referrers, Popular{Name: r.GetReferrer(), Count: r.GetCount(), Uniques: r.GetUniques()})
+	}
+
+	var paths []*github.TrafficPath
+	err = call(ctx, func() (resp *github.Response, err error) {
+		paths, resp, err = client.Repositories.ListTrafficPaths(ctx, owner, repo)
+		return resp, err
+	})
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		stat.Paths = append(stat.Paths, Popular{Name: p.GetPath(), Count: p.GetCount(), Uniques: p.GetUniques()})
+	}
+	return nil
+}
+
+// getStarGrowth sets the number of stars stat gained within growthWindow.
+// Stargazers are listed from the oldest so it starts from the last page.
+func getStarGrowth(ctx context.Context, client *github.Client, owner, repo string, stat *Stat) error {
+	since := time.Now().Add(-growthWindow)
+	opts := &github.ListOptions{PerPage: 100}
+	for page := -1; page != 0; {
+		if page > 0 {
+			opts.Page = page
+		}
+		var stargazers []*github.Stargazer
+		var resp *github.Response
+		err := call(ctx, func() (_ *github.Response, err error) {
+			stargazers, resp, err = client.Activity.ListStargazers(ctx, owner, repo, opts)
+			return resp, err
+		})
+		if err != nil {
+			return err
+		}
+		if page < 0 && resp.LastPage > 0 {
+			page = resp.LastPage // got the first page, jump to the last one
+			continue
+		}
+
+		for i := len(stargazers) - 1; i >= 0; i-- {
+			if stargazers[i].GetStarredAt().Before(since) {
+				return nil
+			}
+			stat.StarGrowth++
+		}
+		page = max(page-1, 0)
+	}
+	return nil
+}
+
+// setLastRelease sets the latest published release of stat.
+func setLastRelease(releases []*github.RepositoryRelease, stat *Stat) {
+	for _, r := range releases {
+		if r.GetDraft() {
+			continue
+		}
+		if published := r.GetPublishedAt().Time; published.After(stat.LastReleased) {
+			stat.LastRelease = r.GetTagName()
+			stat.LastReleased = published
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package ghstats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// table returns values of top N stats in columns. Values that couldn't be
+// fetched are nil.
+func (stats Stats) table(topN int, cols []column) [][]any {
+	var rows [][]any
+	for n, s := range stats {
+		if n == topN {
+			break
+		}
+		var row []any
+		for _, col := range cols {
+			if col.err != nil && col.err(s) != nil {
+				row = append(row, nil)
+				continue
+			}
+			row = append(row, col.value(s))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// totals returns sums of columns that have total. Other columns are empty.
+func totals(cols []column, rows [][]any) []any {
+	sums := make([]int, len(cols))
+	for _, row := range rows {
+		for i, v := range row {
+			if n, ok := v.(int); ok && cols[i].total {
+				sums[i] += n
+			}
+		}
+	}
+	var footer []any
+	for i, col := range cols {
+		if col.total {
+			footer = append(footer, sums[i])
+		} else {
+			footer = append(footer, "")
+		}
+	}
+	return footer
+}
+
+// format formats v for text outputs.
+func format(v any) string {
+	if v == nil {
+		return "?"
+	}
+	return fmt.Sprint(v)
+}
+
+// Print prints a table with top N stats and selected columns to w. Values
+// that couldn't be fetched are printed as question marks.
+func (stats Stats) Print(w io.Writer, topN int, columns []string) error {
+	cols, err := lookupColumns(columns)
+	if err != nil {
+		return err
+	}
+
+	tw := new(tabwriter.Writer).Init(w, 0, 8, 2, ' ', 0)
+	printRow := func(f func(i int, c column) string) {
+		var cells []string
+		for i, c := range cols {
+			cells = append(cells, f(i, c))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	printRow(func(_ int, c column) string { return c.header })
+	printRow(func(_ int, c column) string { return strings.Repeat("-", len(c.header)) })
+	rows := stats.table(topN, cols)
+	for _, row := range rows {
+		printRow(func(i int, _ column) string { return format(row[i]) })
+	}
+
+	// Print footer.
+	printRow(func(_ int, c column) string {
+		if c.total {
+			return strings.Repeat("-", len(c.header))
+		}
+		return strings.Repeat(" ", len(c.header))
+	})
+	footer := totals(cols, rows)
+	printRow(func(i int, _ column) string { return fmt.Sprint(footer[i]) })
+
+	return tw.Flush()
+}
+
+// WriteJSON writes top N stats as JSON array of objects with selected columns
+// as keys. Values that couldn't be fetched are null.
+func (stats Stats) WriteJSON(w io.Writer, topN int, columns []string) error {
+	cols, err := lookupColumns(columns)
+	if err != nil {
+		return err
+	}
+	objects := []map[string]any{}
+	for _, row := range stats.table(topN, cols) {
+		obj := make(map[string]any)
+		for i, c := range cols {
+			obj[c.name] = row[i]
+		}
+		objects = append(objects, obj)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(objects)
+}
+
+// WriteCSV writes top N stats with selected columns as CSV with header.
+// Values that couldn't be fetched are empty.
+func (stats Stats) WriteCSV(w io.Writer, topN int, columns []string) error {
+	cols, err := lookupColumns(columns)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	var header []string
+	for _, c := range cols {
+		header = append(header, c.header)
+	}
+	cw.Write(header)
+	for _, row := range stats.table(topN, cols) {
+		var record []string
+		for _, v := range row {
+			if v == nil {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, fmt.Sprint(v))
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown writes top N stats with selected columns as Markdown table
+// with totals in the last row.
+func (stats Stats) WriteMarkdown(w io.Writer, topN int, columns []string) error {
+	cols, err := lookupColumns(columns)
+	if err != nil {
+		return err
+	}
+	writeRow := func(f func(i int, c column) string) error {
+		var cells []string
+		for i, c := range cols {
+			cells = append(cells, strings.ReplaceAll(f(i, c), "|", `\|`))
+		}
+		_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+		return err
+	}
+
+	writeRow(func(_ int, c column) string { return c.header })
+	writeRow(func(_ int, c column) string {
+		if c.total {
+			return "---:" // align numbers right
+		}
+		return "---"
+	})
+	rows := stats.table(topN, cols)
+	for _, row := range rows {
+		writeRow(func(i int, _ column) string { return format(row[i]) })
+	}
+	footer := totals(cols, rows)
+	return writeRow(func(i int, c column) string {
+		if c.total {
+			return fmt.Sprintf("**%d**", footer[i])
+		}
+		return ""
+	})
+}
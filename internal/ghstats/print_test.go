@@ -0,0 +1,81 @@
+package ghstats
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var printStats = Stats{
+	{Owner: "user", Repository: "repo1", Stars: 3, UniqueVisitors: 10, ReleaseDownloads: 1},
+	{Owner: "user", Repository: "repo2", Stars: 2, UniqueVisitors: 5, ReleasesErr: errors.New("failed")},
+	{Owner: "user", Repository: "repo3", Stars: 1, UniqueVisitors: 1, ReleaseDownloads: 2},
+}
+
+func TestStatsPrintPrintsTotalsOfTopN(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := printStats.Print(&buf, 2, []string{"repository", "stars", "visitors", "downloads"}); err != nil {
+		t.Fatal(err)
+	}
+	want := `Repository  Stars  Visitors (2w)  Release downloads
+----------  -----  -------------  -----------------
+repo1       3      10             1
+repo2       2      5              ?
+            -----  -------------  -----------------
+            5      15             1
+`
+	if got := buf.String(); got != want {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestStatsWriteMarkdown(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := printStats.WriteMarkdown(&buf, 10, []string{"repository", "stars"}); err != nil {
+		t.Fatal(err)
+	}
+	want := `| Repository | Stars |
+| --- | ---: |
+| repo1 | 3 |
+| repo2 | 2 |
+| repo3 | 1 |
+|  | **6** |
+`
+	if got := buf.String(); got != want {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestStatsWriteCSV(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := printStats.WriteCSV(&buf, 2, []string{"repository", "downloads"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "Repository,Release downloads\nrepo1,1\nrepo2,\n"
+	if got := buf.String(); got != want {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestStatsWriteJSON(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := printStats.WriteJSON(&buf, 1, []string{"repository", "downloads"}); err != nil {
+		t.Fatal(err)
+	}
+	want := `[
+  {
+    "downloads": 1,
+    "repository": "repo1"
+  }
+]
+`
+	if got := buf.String(); got != want {
+		t.Error(cmp.Diff(want, got))
+	}
+}
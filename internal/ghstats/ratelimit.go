@@ -0,0 +1,56 @@
+package ghstats
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// maxRetries is how many times an API call is retried after hitting a rate
+// limit.
+const maxRetries = 3
+
+// secondaryRateLimitWait is how long to wait after hitting a secondary rate
+// limit when GitHub doesn't say (via Retry-After header).
+const secondaryRateLimitWait = time.Minute
+
+// call calls GitHub API via fn. When a rate limit is exceeded it waits and
+// retries the call. It gives up when ctx is done.
+func call(ctx context.Context, fn func() (*github.Response, error)) error {
+	for attempt := 0; ; attempt++ {
+		_, err := fn()
+		wait, ok := rateLimitWait(err)
+		if !ok || attempt == maxRetries {
+			return err
+		}
+		log.Printf("rate limit exceeded, waiting %v", wait.Round(time.Second))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// rateLimitWait returns how long to wait if err means that primary or
+// secondary rate limit was exceeded.
+func rateLimitWait(err error) (time.Duration, bool) {
+	var rateLimitErr *github.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		wait := time.Until(rateLimitErr.Rate.Reset.Time)
+		return max(wait, 0) + time.Second, true
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return *abuseErr.RetryAfter, true
+		}
+		return secondaryRateLimitWait, true
+	}
+	return 0, false
+}
@@ -0,0 +1,48 @@
+package ghstats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v50/github"
+)
+
+func TestRateLimitWait(t *testing.T) {
+	retryAfter := 5 * time.Second
+	testcases := []struct {
+		name   string
+		err    error
+		want   time.Duration
+		wantOk bool
+	}{
+		{"no error", nil, 0, false},
+		{"other error", errors.New("not found"), 0, false},
+		{"secondary with Retry-After", &github.AbuseRateLimitError{RetryAfter: &retryAfter}, retryAfter, true},
+		{"secondary without Retry-After", &github.AbuseRateLimitError{}, secondaryRateLimitWait, true},
+		{"primary already reset", &github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: time.Now().Add(-time.Hour)}}}, time.Second, true},
+	}
+	for _, tc := range testcases {
+		got, ok := rateLimitWait(tc.err)
+		if got != tc.want || ok != tc.wantOk {
+			t.Errorf("%s: want %v %v, got %v %v", tc.name, tc.want, tc.wantOk, got, ok)
+		}
+	}
+}
+
+func TestCallGivesUpWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int
+	err := call(ctx, func() (*github.Response, error) {
+		calls++
+		return nil, &github.AbuseRateLimitError{}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want context.Canceled, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("want 1 call, got %d", calls)
+	}
+}
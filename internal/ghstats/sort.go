@@ -0,0 +1,51 @@
+package ghstats
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Sort sorts stats by keys. Key is a column name optionally followed by :asc
+// or :desc, e.g. stars:asc. Without order, numbers and dates are sorted in
+// descending order and text in ascending order. Ties are sorted by next keys
+// and finally by repository and owner.
+func (stats Stats) Sort(keys ...string) error {
+	type sortKey struct {
+		column
+		desc bool
+	}
+	var sortKeys []sortKey
+	for _, key := range append(slices.Clone(keys), "repository:asc", "owner:asc") {
+		name, order, _ := strings.Cut(key, ":")
+		col, err := lookupColumn(name)
+		if err != nil {
+			return err
+		}
+		k := sortKey{column: col, desc: col.desc}
+		switch order {
+		case "":
+		case "asc":
+			k.desc = false
+		case "desc":
+			k.desc = true
+		default:
+			return fmt.Errorf("unknown sort order %q, use asc or desc", order)
+		}
+		sortKeys = append(sortKeys, k)
+	}
+
+	slices.SortStableFunc(stats, func(x, y Stat) int {
+		for _, k := range sortKeys {
+			c := k.compare(x, y)
+			if k.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return nil
+}
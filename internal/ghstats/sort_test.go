@@ -0,0 +1,111 @@
+package ghstats
+
+import (
+	"testing"
+	"time"
+)
+
+// lowHigh returns two stats where the first one has lower values in all
+// columns.
+func lowHigh() Stats {
+	low := Stat{
+		Owner: "a", Repository: "a", Pushed: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Stars: 1, Forks: 1, Watchers: 1, OpenIssues: 1, OpenPRs: 1, Contributors: 1,
+		UniqueVisitors: 1, UniqueCloners: 1, ReleaseDownloads: 1,
+		LastRelease: "v1", LastReleased: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), StarGrowth: 1,
+		Referrers: []Popular{{Name: "a"}}, Paths: []Popular{{Name: "/a"}},
+		Since: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), TotalViews: 1, TotalClones: 1, ViewsTrend: 1,
+		Traffic: []Day{{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), UniqueVisitors: 1}},
+	}
+	high := Stat{
+		Owner: "b", Repository: "b", Pushed: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+		Stars: 2, Forks: 2, Watchers: 2, OpenIssues: 2, OpenPRs: 2, Contributors: 2,
+		UniqueVisitors: 2, UniqueCloners: 2, ReleaseDownloads: 2,
+		LastRelease: "v2", LastReleased: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), StarGrowth: 2,
+		Referrers: []Popular{{Name: "b"}}, Paths: []Popular{{Name: "/b"}},
+		Since: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), TotalViews: 2, TotalClones: 2, ViewsTrend: 2,
+		Traffic: []Day{{Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), UniqueVisitors: 2}},
+	}
+	return Stats{high, low}
+}
+
+func TestStatsSortByEveryColumn(t *testing.T) {
+	t.Parallel()
+	for _, name := range ColumnNames() {
+		stats := lowHigh()
+		if err := stats.Sort(name + ":asc"); err != nil {
+			t.Fatal(err)
+		}
+		if stats[0].Repository != "a" {
+			t.Errorf("%s:asc: want a first, got %s", name, stats[0].Repository)
+		}
+		if err := stats.Sort(name + ":desc"); err != nil {
+			t.Fatal(err)
+		}
+		if stats[0].Repository != "b" {
+			t.Errorf("%s:desc: want b first, got %s", name, stats[0].Repository)
+		}
+	}
+}
+
+func TestStatsSortDefaultOrder(t *testing.T) {
+	t.Parallel()
+	testcases := []struct {
+		key   string
+		first string
+	}{
+		{"repository", "a"},
+		{"stars", "b"},
+		{"pushed", "b"},
+		{"since", "a"},
+	}
+	for _, tc := range testcases {
+		stats := lowHigh()
+		if err := stats.Sort(tc.key); err != nil {
+			t.Fatal(err)
+		}
+		if stats[0].Repository != tc.first {
+			t.Errorf("%s: want %s first, got %s", tc.key, tc.first, stats[0].Repository)
+		}
+	}
+}
+
+func TestStatsSortByMultipleKeys(t *testing.T) {
+	t.Parallel()
+	stats := Stats{
+		{Repository: "a", Stars: 1, Forks: 1},
+		{Repository: "b", Stars: 2, Forks: 1},
+		{Repository: "c", Stars: 1, Forks: 2},
+	}
+	if err := stats.Sort("forks", "stars:asc"); err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	for _, s := range stats {
+		got += s.Repository
+	}
+	if want := "cab"; got != want {
+		t.Errorf("want order %s, got %s", want, got)
+	}
+}
+
+func TestStatsSortFailsOnUnknownKey(t *testing.T) {
+	t.Parallel()
+	for _, key := range []string{"nosuchcolumn", "stars:up"} {
+		if err := lowHigh().Sort(key); err == nil {
+			t.Errorf("%s: want error, got nil", key)
+		}
+	}
+}
+
+func TestStatsSortKeepsKeys(t *testing.T) {
+	t.Parallel()
+	keys := make([]string, 1, 3)
+	keys[0] = "stars"
+	if err := lowHigh().Sort(keys...); err != nil {
+		t.Fatal(err)
+	}
+	if got := keys[:cap(keys)]; got[1] != "" || got[2] != "" {
+		t.Errorf("Sort wrote to the keys' backing array: %q", got)
+	}
+}